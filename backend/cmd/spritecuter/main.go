@@ -0,0 +1,329 @@
+package main
+
+import (
+	"SpriteCuter/core"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	// 解析命令行参数
+	pngFile := flag.String("input", "", "PNG文件路径")
+	formats := flag.String("formats", strings.Join(defaultFormats(), ","), "导出格式，多个格式用逗号分隔，可选 "+strings.Join(formatIDs(), "、"))
+	listFormats := flag.Bool("list-formats", false, "列出所有导出格式及其选项")
+	atlasFile := flag.String("atlas", "", "图集描述文件路径（TexturePacker JSON、Cocos2d plist或LibGDX .atlas），指定后按其中的区域切割")
+	grid := flag.String("grid", "", "按固定网格切割，格式为 宽x高，如 32x32")
+	gridMargin := flag.Int("grid-margin", 0, "网格切割时图集边缘的留白")
+	gridSpacing := flag.Int("grid-spacing", 0, "网格切割时单元格之间的间距")
+	quantizeColors := flag.Int("quantize", 0, "将精灵图量化为指定颜色数（2-256）的调色板图，0表示不量化")
+	quantizeMethod := flag.String("quantize-method", core.QuantizeMedianCut, "量化算法，mediancut 或 octree")
+	quantizeDither := flag.Bool("quantize-dither", false, "量化时使用Floyd–Steinberg抖动")
+	quantizeShared := flag.Bool("quantize-shared", false, "整张图集共用一个调色板")
+	pngOpts := core.PNGOptions{Text: map[string]string{}}
+	flag.StringVar(&pngOpts.Compression, "png-compression", "default", "PNG压缩级别，default、none、speed 或 best")
+	flag.BoolVar(&pngOpts.StripMetadata, "png-strip-metadata", false, "去除gamma、ICC和文本块")
+	flag.BoolVar(&pngOpts.Metadata, "png-metadata", false, "在精灵图中写入精灵名称、来源图集和区域")
+	flag.Func("png-text", "写入精灵图的自定义文本块，格式为 关键字=内容，可重复指定", func(value string) error {
+		key, text, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("格式应为 关键字=内容: %s", value)
+		}
+		pngOpts.Text[key] = text
+		return nil
+	})
+	options := formatFlags()
+	flag.Parse()
+
+	if *listFormats {
+		printFormats()
+		return
+	}
+	if *pngFile == "" {
+		log.Fatal("请提供PNG文件路径作为参数，使用 -input 参数")
+	}
+
+	// 指定了选项的格式即使不在 -formats 中也会导出
+	var requests []core.FormatRequest
+	if *formats != "" {
+		for _, id := range strings.Split(*formats, ",") {
+			requests = append(requests, core.FormatRequest{ID: strings.TrimSpace(id)})
+		}
+	}
+	for _, id := range formatIDs() {
+		if len(options[id]) > 0 && !slices.ContainsFunc(requests, func(r core.FormatRequest) bool { return r.ID == id }) {
+			requests = append(requests, core.FormatRequest{ID: id})
+		}
+	}
+	for i, req := range requests {
+		if len(options[req.ID]) > 0 {
+			data, err := json.Marshal(options[req.ID])
+			if err != nil {
+				log.Fatal(err)
+			}
+			requests[i].Options = data
+		}
+	}
+	requests, err := core.ResolveFormats(requests)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cssOpts, err := core.ResolveCSSOptions(requests)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var quantize *core.QuantizeOptions
+	if *quantizeColors != 0 {
+		quantize = &core.QuantizeOptions{Colors: *quantizeColors, Method: *quantizeMethod, Dither: *quantizeDither, Shared: *quantizeShared}
+		if err := quantize.Validate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := pngOpts.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	var gridOpts *core.GridOptions
+	if *grid != "" {
+		gridOpts = &core.GridOptions{Margin: *gridMargin, Spacing: *gridSpacing}
+		if _, err := fmt.Sscanf(*grid, "%dx%d", &gridOpts.Width, &gridOpts.Height); err != nil {
+			log.Fatalf("网格参数格式错误: %s", *grid)
+		}
+		if err := gridOpts.Validate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if !fileExists(*pngFile) {
+		log.Fatalf("文件不存在: %s", *pngFile)
+	}
+
+	// 读取PNG文件
+	img, err := readPNG(*pngFile)
+	if err != nil {
+		log.Fatalf("读取图片时发生错误: %v", err)
+	}
+
+	// 读取图集中的gamma、ICC和文本块，供精灵图继承
+	chunks, err := readPNGChunks(*pngFile)
+	if err != nil {
+		log.Fatalf("读取PNG数据块时发生错误: %v", err)
+	}
+
+	// 获取输出目录名
+	outDir := strings.TrimSuffix(filepath.Base(*pngFile), ".png")
+	if outDir == "" || outDir == "." || outDir == ".." {
+		log.Fatalf("文件名无效: %s", *pngFile)
+	}
+
+	// 创建输出目录，清除上次导出留下的文件
+	if err := createDir("export"); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.RemoveAll("export/" + outDir); err != nil {
+		log.Fatal(err)
+	}
+	if err := createDir("export/" + outDir); err != nil {
+		log.Fatal(err)
+	}
+
+	// 优先按图集描述文件切割，其次按网格或图集中嵌入的帧信息，否则检测并提取精灵
+	var spritesArray []core.Frame
+	if *atlasFile != "" {
+		data, err := os.ReadFile(*atlasFile)
+		if err != nil {
+			log.Fatalf("读取图集描述文件时发生错误: %v", err)
+		}
+		spritesArray, err = core.ParseAtlas(*atlasFile, data)
+		if err != nil {
+			log.Fatalf("解析图集描述文件时发生错误: %v", err)
+		}
+	} else if gridOpts != nil {
+		spritesArray = core.FramesFromRects(core.GetGridSprites(img, *gridOpts))
+	} else {
+		var embedded bool
+		spritesArray, embedded, err = core.EmbeddedFrames(chunks)
+		if err != nil {
+			log.Fatalf("解析图集帧信息时发生错误: %v", err)
+		}
+		if embedded {
+			fmt.Printf("使用图集中嵌入的 %d 个帧区域\n", len(spritesArray))
+		} else {
+			spritesArray = core.FramesFromRects(core.GetSprites(img))
+		}
+	}
+	if err := core.ValidateFrames(spritesArray, img.Bounds()); err != nil {
+		log.Fatalf("图集帧信息无效: %v", err)
+	}
+	for i, frame := range spritesArray {
+		fmt.Printf("精灵 %d: %s %+v\n", i, core.FrameName(i, frame), frame.Rect)
+	}
+
+	// 整张图集共用一个调色板
+	saveOpts := core.SaveOptions{
+		Quantize: quantize,
+		PNG:      pngOpts,
+		Source:   filepath.Base(*pngFile),
+		Chunks:   core.MetadataChunks(chunks),
+	}
+	if quantize != nil && quantize.Shared {
+		saveOpts.Palette = core.BuildPalette(img, core.FrameRects(spritesArray), quantize.Colors, quantize.Method)
+	}
+
+	// 导出的文件引用复制到输出目录的图集
+	sheet := core.NewSheet(outDir+".png", img, spritesArray)
+	if *atlasFile == "" {
+		sheet.Grid = gridOpts
+	}
+	var result core.SaveResult
+	ctx := core.ExportContext{
+		Sheet:    sheet,
+		BaseName: outDir,
+		Name:     outDir,
+		CSS:      cssOpts,
+		Save:     saveOpts,
+		Result:   &result,
+		ReadFile: func(name string) ([]byte, string, error) {
+			data, err := os.ReadFile(name)
+			return data, name, err
+		},
+		Logf: log.Printf,
+	}
+
+	// 按指定的格式依次导出
+	for _, req := range requests {
+		exporter, _ := core.LookupExporter(req.ID)
+		files, err := exporter.Export(ctx, req.Options)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, file := range files {
+			path := filepath.Join("export", outDir, filepath.FromSlash(file.Name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				log.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+		fmt.Printf("%s文件已保存!\n", req.ID)
+	}
+	if quantize != nil && slices.ContainsFunc(requests, func(r core.FormatRequest) bool { return r.ID == core.SpritesFormat }) {
+		fmt.Printf("精灵图量化前 %d 字节，量化后 %d 字节\n", result.OriginalSize, result.Size)
+	}
+}
+
+// formatIDs 返回所有导出格式的ID
+func formatIDs() []string {
+	var ids []string
+	for _, info := range core.Formats() {
+		ids = append(ids, info.ID)
+	}
+	return ids
+}
+
+// defaultFormats 返回未指定时导出的格式
+func defaultFormats() []string {
+	var ids []string
+	for _, info := range core.Formats() {
+		if info.Default {
+			ids = append(ids, info.ID)
+		}
+	}
+	return ids
+}
+
+// formatFlags 为每个导出格式的选项注册 -格式.选项 参数，返回按格式记录的选项值
+func formatFlags() map[string]map[string]any {
+	options := map[string]map[string]any{}
+	for _, info := range core.Formats() {
+		for _, opt := range info.Options {
+			id, name, typ := info.ID, opt.Name, opt.Type
+			usage := opt.Description
+			if opt.Default != nil && opt.Default != "" {
+				usage += fmt.Sprintf("，默认 %v", opt.Default)
+			}
+			set := func(value string) error {
+				var v any = value
+				var err error
+				switch typ {
+				case "integer":
+					v, err = strconv.Atoi(value)
+				case "number":
+					v, err = strconv.ParseFloat(value, 64)
+				case "boolean":
+					v, err = strconv.ParseBool(value)
+				}
+				if err != nil {
+					return err
+				}
+				if options[id] == nil {
+					options[id] = map[string]any{}
+				}
+				options[id][name] = v
+				return nil
+			}
+			if typ == "boolean" {
+				flag.BoolFunc(id+"."+name, usage, set)
+			} else {
+				flag.Func(id+"."+name, usage, set)
+			}
+		}
+	}
+	return options
+}
+
+// printFormats 输出所有导出格式及其选项
+func printFormats() {
+	for _, info := range core.Formats() {
+		fmt.Printf("%s\t%s：%s\n", info.ID, info.Name, info.Description)
+		for _, opt := range info.Options {
+			fmt.Printf("  -%s.%s (%s)\t%s", info.ID, opt.Name, opt.Type, opt.Description)
+			if opt.Default != nil && opt.Default != "" {
+				fmt.Printf("，默认 %v", opt.Default)
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+func readPNGChunks(path string) ([]core.PNGChunk, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return core.ReadPNGChunks(file)
+}
+
+func createDir(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return os.Mkdir(path, 0755)
+	}
+	return nil
+}
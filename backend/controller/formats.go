@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"SpriteCuter/core"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFormats 返回所有导出格式的说明、选项和默认值
+func GetFormats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"formats": core.Formats()})
+}
@@ -3,19 +3,31 @@ package controller
 import (
 	"SpriteCuter/core"
 	"SpriteCuter/utils"
+	"errors"
 	"image"
 	"image/png"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 )
 
+// uploadSuffix 匹配上传时添加到文件名中的随机字符串和时间戳
+var uploadSuffix = regexp.MustCompile(`_[0-9A-Za-z]{8}_[0-9]+(\.[^.]*)?$`)
+
 // ProcessImage 处理图片切割请求
 func ProcessImage(c *gin.Context) {
 	var req struct {
-		Filename string `json:"filename" binding:"required"`
+		Filename string                `json:"filename" binding:"required"`
+		Quantize *core.QuantizeOptions `json:"quantize"`
+		PNG      core.PNGOptions       `json:"png"`
+		Atlas    string                `json:"atlas"`   // 已上传的图集描述文件
+		Grid     *core.GridOptions     `json:"grid"`    // 按固定网格切割
+		Formats  []core.FormatRequest  `json:"formats"` // 导出格式及其选项，为空时导出默认格式
 	}
 
 	// 绑定请求参数
@@ -25,6 +37,45 @@ func ProcessImage(c *gin.Context) {
 		return
 	}
 
+	// 校验量化参数
+	if req.Quantize != nil {
+		if err := req.Quantize.Validate(); err != nil {
+			utils.ErrorLogger.Printf("请求参数错误: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+			return
+		}
+	}
+
+	// 校验PNG编码参数
+	if err := req.PNG.Validate(); err != nil {
+		utils.ErrorLogger.Printf("请求参数错误: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	// 校验网格参数
+	if req.Grid != nil {
+		if err := req.Grid.Validate(); err != nil {
+			utils.ErrorLogger.Printf("请求参数错误: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+			return
+		}
+	}
+
+	// 校验导出格式及选项，补全依赖的格式
+	formats, err := core.ResolveFormats(req.Formats)
+	if err != nil {
+		utils.ErrorLogger.Printf("请求参数错误: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+	cssOpts, err := core.ResolveCSSOptions(formats)
+	if err != nil {
+		utils.ErrorLogger.Printf("请求参数错误: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
 	// 检查文件是否存在
 	uploadPath := filepath.Join("./uploads/", req.Filename)
 	if !utils.FileExists(uploadPath) {
@@ -41,45 +92,122 @@ func ProcessImage(c *gin.Context) {
 		return
 	}
 
+	// 读取图集中的gamma、ICC和文本块，供精灵图继承
+	chunks, err := readPNGChunks(uploadPath)
+	if err != nil {
+		utils.ErrorLogger.Printf("读取PNG数据块时发生错误: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取PNG数据块时发生错误: " + err.Error()})
+		return
+	}
+
 	// 获取输出目录名
 	outDir := utils.GetBaseName(req.Filename)
+	if outDir == "" || outDir == "." || outDir == ".." {
+		utils.ErrorLogger.Printf("文件名无效: %s", req.Filename)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文件名无效"})
+		return
+	}
 	exportPath := filepath.Join("./export/", outDir)
 
-	// 创建输出目录
-	if err := utils.CreateDir(exportPath); err != nil {
+	// 创建输出目录，清除上次导出留下的文件
+	if err := utils.ResetDir(exportPath); err != nil {
 		utils.ErrorLogger.Printf("创建输出目录失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建输出目录失败: " + err.Error()})
 		return
 	}
 
-	// 调用核心逻辑进行图片切割
-	spritesArray := core.GetSprites(img)
+	// 优先按图集描述文件切割，其次按网格或图集中嵌入的帧信息，否则调用核心逻辑检测
+	var spritesArray []core.Frame
+	if req.Atlas != "" {
+		atlasPath := filepath.Join("./uploads/", filepath.Base(req.Atlas))
+		if !utils.FileExists(atlasPath) {
+			utils.ErrorLogger.Printf("文件不存在: %s", req.Atlas)
+			c.JSON(http.StatusNotFound, gin.H{"error": "图集描述文件不存在"})
+			return
+		}
+		spritesArray, err = readAtlas(atlasPath)
+		if err != nil {
+			utils.ErrorLogger.Printf("解析图集描述文件失败: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "解析图集描述文件失败: " + err.Error()})
+			return
+		}
+	} else if req.Grid != nil {
+		spritesArray = core.FramesFromRects(core.GetGridSprites(img, *req.Grid))
+	} else {
+		var embedded bool
+		spritesArray, embedded, err = embeddedSprites(chunks)
+		if err != nil {
+			utils.ErrorLogger.Printf("解析图集帧信息失败: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "解析图集帧信息失败: " + err.Error()})
+			return
+		}
+		if !embedded {
+			spritesArray = core.FramesFromRects(core.GetSprites(img))
+		}
+	}
 
-	// 生成CSS文件
-	css := core.GetCSS(spritesArray, req.Filename)
-	//utils.ErrorLogger.Printf("CSS内容: %v", css)
-	cssPath := filepath.Join(exportPath, outDir+".css")
-	if err := os.WriteFile(cssPath, []byte(css), 0644); err != nil {
-		utils.ErrorLogger.Printf("保存CSS文件失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存CSS文件失败: " + err.Error()})
+	// 检查读取的帧区域是否位于图集内
+	if err := core.ValidateFrames(spritesArray, img.Bounds()); err != nil {
+		utils.ErrorLogger.Printf("图集帧信息无效: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "图集帧信息无效: " + err.Error()})
 		return
 	}
 
-	// 生成JSON文件
-	json := core.GetJson(spritesArray, req.Filename)
-	//utils.ErrorLogger.Printf("JSON内容: %v", json)
-	jsonPath := filepath.Join(exportPath, outDir+".json")
-	if err := os.WriteFile(jsonPath, []byte(json), 0644); err != nil {
-		utils.ErrorLogger.Printf("保存JSON文件失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存JSON文件失败: " + err.Error()})
-		return
+	// 整张图集共用一个调色板
+	saveOpts := core.SaveOptions{
+		Quantize: req.Quantize,
+		PNG:      req.PNG,
+		Source:   req.Filename,
+		Chunks:   core.MetadataChunks(chunks),
+	}
+	if req.Quantize != nil && req.Quantize.Shared {
+		saveOpts.Palette = core.BuildPalette(img, core.FrameRects(spritesArray), req.Quantize.Colors, req.Quantize.Method)
 	}
 
-	// 切割并保存精灵图
-	for i, rect := range spritesArray {
-		if err := core.SaveSprite(img, rect, outDir, i); err != nil {
-			// 记录错误但继续处理其他精灵
-			utils.ErrorLogger.Printf("保存精灵 %d 时出错: %v", i, err)
+	// 导出的文件引用复制到输出目录的图集
+	sheet := core.NewSheet(outDir+".png", img, spritesArray)
+	if req.Atlas == "" {
+		sheet.Grid = req.Grid
+	}
+	var result core.SaveResult
+	ctx := core.ExportContext{
+		Sheet:    sheet,
+		BaseName: outDir,
+		Name:     uploadSuffix.ReplaceAllString(outDir, ""),
+		CSS:      cssOpts,
+		Save:     saveOpts,
+		Result:   &result,
+		ReadFile: readUpload,
+		Logf:     utils.ErrorLogger.Printf,
+	}
+
+	// 按请求的格式依次导出
+	for _, format := range formats {
+		exporter, _ := core.LookupExporter(format.ID)
+		files, err := exporter.Export(ctx, format.Options)
+		if err == nil {
+			err = writeExportFiles(exportPath, files)
+		}
+		var templateErr *core.TemplateError
+		if errors.As(err, &templateErr) {
+			utils.ErrorLogger.Printf("导出%s失败: %v", format.ID, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "导出" + format.ID + "失败: " + err.Error(), "line": templateErr.Line})
+			return
+		}
+		if errors.Is(err, core.ErrUnsupportedFrame) || errors.Is(err, core.ErrInvalidSource) {
+			utils.ErrorLogger.Printf("导出%s失败: %v", format.ID, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "导出" + format.ID + "失败: " + err.Error()})
+			return
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			utils.ErrorLogger.Printf("导出%s失败: %v", format.ID, err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "导出" + format.ID + "失败: 引用的文件不存在"})
+			return
+		}
+		if err != nil {
+			utils.ErrorLogger.Printf("导出%s失败: %v", format.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "导出" + format.ID + "失败: " + err.Error()})
+			return
 		}
 	}
 
@@ -93,10 +221,16 @@ func ProcessImage(c *gin.Context) {
 	}
 
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"message":      "图片切割成功",
 		"download_url": "/api/v1/download/" + zipFilename,
-	})
+	}
+	// 导出精灵图时返回量化前后的大小
+	if req.Quantize != nil && slices.ContainsFunc(formats, func(f core.FormatRequest) bool { return f.ID == core.SpritesFormat }) {
+		resp["size_before"] = result.OriginalSize
+		resp["size_after"] = result.Size
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // readPNG 读取PNG文件
@@ -109,3 +243,58 @@ func readPNG(path string) (image.Image, error) {
 
 	return png.Decode(file)
 }
+
+// embeddedSprites 读取图集中嵌入的帧信息
+func embeddedSprites(chunks []core.PNGChunk) ([]core.Frame, bool, error) {
+	frames, ok, err := core.EmbeddedFrames(chunks)
+	if ok {
+		utils.InfoLogger.Printf("使用图集中嵌入的 %d 个帧区域", len(frames))
+	}
+	return frames, ok, err
+}
+
+// writeExportFiles 将导出的文件写入输出目录
+func writeExportFiles(exportPath string, files []core.ExportFile) error {
+	for _, file := range files {
+		path := filepath.Join(exportPath, filepath.FromSlash(file.Name))
+		if err := utils.CreateDir(filepath.Dir(path)); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readUpload 读取导出选项中引用的已上传文件，返回内容和上传前的文件名
+func readUpload(name string) ([]byte, string, error) {
+	name = filepath.Base(name)
+	data, err := os.ReadFile(filepath.Join("./uploads/", name))
+	return data, uploadSuffix.ReplaceAllString(name, ""), err
+}
+
+// readAtlas 读取图集描述文件
+func readAtlas(path string) ([]core.Frame, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := core.ParseAtlas(path, data)
+	if err != nil {
+		return nil, err
+	}
+	utils.InfoLogger.Printf("使用图集描述文件中的 %d 个帧区域", len(frames))
+	return frames, nil
+}
+
+// readPNGChunks 读取PNG文件的数据块
+func readPNGChunks(path string) ([]core.PNGChunk, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return core.ReadPNGChunks(file)
+}
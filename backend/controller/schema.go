@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"SpriteCuter/core"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJSONSchema 返回导出JSON对应的JSON Schema
+func GetJSONSchema(c *gin.Context) {
+	c.Data(http.StatusOK, "application/schema+json", core.JSONSchema)
+}
@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"SpriteCuter/core"
 	"SpriteCuter/utils"
 	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,8 +32,27 @@ func UploadImage(c *gin.Context) {
 
 	// 返回成功响应
 	utils.InfoLogger.Printf("文件上传成功: %s", uniqueFilename)
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"message":  "文件上传成功",
 		"filename": uniqueFilename,
-	})
+	}
+
+	// 读取PNG中的文本块，便于追溯之前导出的精灵来源
+	if strings.EqualFold(filepath.Ext(uniqueFilename), ".png") {
+		if chunks, err := readPNGChunks(filePath); err != nil {
+			utils.ErrorLogger.Printf("读取PNG数据块失败: %v", err)
+		} else if text, err := core.ParseTextChunks(chunks); err != nil {
+			utils.ErrorLogger.Printf("解析PNG文本块失败: %v", err)
+		} else if len(text) > 0 {
+			// 图集帧信息内容较大，只返回帧数量
+			if atlas, ok := text[core.MetaKeyAtlas]; ok {
+				delete(text, core.MetaKeyAtlas)
+				if rects, err := core.ParseJson([]byte(atlas)); err == nil {
+					resp["atlas_frames"] = len(rects)
+				}
+			}
+			resp["metadata"] = text
+		}
+	}
+	c.JSON(http.StatusOK, resp)
 }
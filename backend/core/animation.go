@@ -0,0 +1,75 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Animation 表示按帧号排列的一组帧
+type Animation struct {
+	Name   string // 动画名称，即去除帧号后的帧名称
+	Frames []int  // 帧在图集中的序号，按帧号排序
+}
+
+// SplitFrameNumber 拆分帧名称末尾以_或-分隔的帧号，如 walk_01.png 拆分为 walk 和 1
+// 名称末尾没有这样的帧号时ok为false
+func SplitFrameNumber(name string) (base string, number int, ok bool) {
+	name = trimImageExt(name)
+	end := len(name)
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+	if start == end || start == 0 || (name[start-1] != '_' && name[start-1] != '-') {
+		return name, -1, false
+	}
+	number, err := strconv.Atoi(name[start:end])
+	if err != nil {
+		return name, -1, false
+	}
+	base = strings.TrimRight(name[:start], "_-")
+	if base == "" {
+		return name, -1, false
+	}
+	return base, number, true
+}
+
+// FrameNumber 拆分帧名称中的动画名称和帧号
+// 未命名的帧（检测出的sprite0、sprite1…）不属于任何动画
+func FrameNumber(frame Frame) (base string, number int, ok bool) {
+	if frame.Name == "" {
+		return "", -1, false
+	}
+	return SplitFrameNumber(frame.Name)
+}
+
+// GroupAnimations 按帧名称末尾的帧号将帧分组为动画
+// 动画按首次出现的顺序排列，未命名或没有帧号的帧不属于任何动画
+func GroupAnimations(frames []Frame) []Animation {
+	var animations []Animation
+	lookup := map[string]int{}
+	numbers := make([]int, len(frames))
+
+	for i, frame := range frames {
+		base, number, ok := FrameNumber(frame)
+		if !ok {
+			continue
+		}
+		numbers[i] = number
+		idx, exists := lookup[base]
+		if !exists {
+			idx = len(animations)
+			lookup[base] = idx
+			animations = append(animations, Animation{Name: base})
+		}
+		animations[idx].Frames = append(animations[idx].Frames, i)
+	}
+
+	for _, animation := range animations {
+		sort.SliceStable(animation.Frames, func(a, b int) bool {
+			return numbers[animation.Frames[a]] < numbers[animation.Frames[b]]
+		})
+	}
+	return animations
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFrameNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		number int
+		ok     bool
+	}{
+		{"walk_01.png", "walk", 1, true},
+		{"run-12", "run", 12, true},
+		{"hero_idle__3", "hero_idle", 3, true},
+		{"walk01", "walk01", -1, false},
+		{"sprite3", "sprite3", -1, false},
+		{"v1.2", "v1.2", -1, false},
+		{"_1", "_1", -1, false},
+		{"idle", "idle", -1, false},
+	}
+	for _, tt := range tests {
+		base, number, ok := SplitFrameNumber(tt.name)
+		if base != tt.base || number != tt.number || ok != tt.ok {
+			t.Errorf("SplitFrameNumber(%q) = %q, %d, %v, want %q, %d, %v", tt.name, base, number, ok, tt.base, tt.number, tt.ok)
+		}
+	}
+}
+
+func TestGroupAnimations(t *testing.T) {
+	frames := []Frame{
+		{Name: "walk_2.png"},
+		{Name: "idle"},
+		{Name: "walk_1.png"},
+		{Name: "jump-10"},
+		{Name: "jump-9"},
+	}
+	want := []Animation{
+		{Name: "walk", Frames: []int{2, 0}},
+		{Name: "jump", Frames: []int{4, 3}},
+	}
+	if got := GroupAnimations(frames); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupAnimations = %v, want %v", got, want)
+	}
+}
+
+func TestGroupAnimationsIgnoresDetectedFrames(t *testing.T) {
+	// 检测出的帧没有名称，不能因为默认名称sprite0、sprite1…合并为一个动画
+	frames := make([]Frame, 4)
+	if got := GroupAnimations(frames); len(got) != 0 {
+		t.Errorf("GroupAnimations = %v, want none", got)
+	}
+}
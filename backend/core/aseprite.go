@@ -0,0 +1,198 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Aseprite JSON 导出格式
+const AsepriteJSON = "aseprite-json"
+
+// AsepriteOptions Aseprite JSON 导出选项
+type AsepriteOptions struct {
+	Duration int  `json:"duration"` // 未指定时长的帧使用的时长（毫秒），默认100
+	Array    bool `json:"array"`    // 输出Array格式，否则输出Hash格式
+}
+
+// withDefaults 补全默认选项
+func (o AsepriteOptions) withDefaults() AsepriteOptions {
+	if o.Duration <= 0 {
+		o.Duration = 100
+	}
+	return o
+}
+
+// aseFrame Aseprite JSON中的帧
+type aseFrame struct {
+	Filename         string `json:"filename,omitempty"`
+	Frame            tpRect `json:"frame"`
+	Rotated          bool   `json:"rotated"`
+	Trimmed          bool   `json:"trimmed"`
+	SpriteSourceSize tpRect `json:"spriteSourceSize"`
+	SourceSize       tpSize `json:"sourceSize"`
+	Duration         int    `json:"duration"`
+}
+
+// aseHashFrames 按帧顺序输出的hash格式frames
+type aseHashFrames []aseFrame
+
+// MarshalJSON 将帧编码为以文件名为键的对象，保持帧的顺序
+func (frames aseHashFrames) MarshalJSON() ([]byte, error) {
+	keys := make([]string, len(frames))
+	values := make([]any, len(frames))
+	for i, frame := range frames {
+		keys[i] = frame.Filename
+		frame.Filename = ""
+		values[i] = frame
+	}
+	return marshalOrderedObject(keys, values)
+}
+
+// aseFrameTag Aseprite JSON中的动画标签
+type aseFrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Color     string `json:"color"`
+}
+
+// aseLayer Aseprite JSON中的图层
+type aseLayer struct {
+	Name      string `json:"name"`
+	Opacity   int    `json:"opacity"`
+	BlendMode string `json:"blendMode"`
+}
+
+// aseSlice Aseprite JSON中的切片
+type aseSlice struct {
+	Name  string        `json:"name"`
+	Color string        `json:"color"`
+	Keys  []aseSliceKey `json:"keys"`
+}
+
+// aseSliceKey 切片在某一帧上的区域和锚点
+type aseSliceKey struct {
+	Frame  int    `json:"frame"`
+	Bounds tpRect `json:"bounds"`
+	Pivot  *aseXY `json:"pivot,omitempty"`
+}
+
+// aseXY Aseprite JSON中的坐标
+type aseXY struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// aseMeta Aseprite JSON中的meta
+type aseMeta struct {
+	tpMeta
+	FrameTags []aseFrameTag `json:"frameTags"`
+	Layers    []aseLayer    `json:"layers"`
+	Slices    []aseSlice    `json:"slices"`
+}
+
+// GetAsepriteJson 生成Aseprite导出的JSON图集格式
+// 按帧名称分组的动画写入frameTags，Aseprite的标签只能引用连续的帧，
+// 因此同一动画的帧按帧号连续输出，不属于任何动画的帧排在最后。
+// 设置了锚点的帧写入同名的slice，锚点为相对原始尺寸的像素坐标。
+// 重复的帧名称加上序号，rotated与TexturePacker相同，有逆时针旋转存放的帧时返回错误
+func GetAsepriteJson(sheet Sheet, opts AsepriteOptions) (string, error) {
+	opts = opts.withDefaults()
+	if err := checkClockwise(sheet.Frames); err != nil {
+		return "", err
+	}
+	names := frameNames(sheet.Frames)
+
+	// 动画的帧在前，其余帧在后
+	order := make([]int, 0, len(sheet.Frames))
+	grouped := make([]bool, len(sheet.Frames))
+	var tags []aseFrameTag
+	for _, animation := range GroupAnimations(sheet.Frames) {
+		tags = append(tags, aseFrameTag{
+			Name:      animation.Name,
+			From:      len(order),
+			To:        len(order) + len(animation.Frames) - 1,
+			Direction: "forward",
+			Color:     "#000000ff",
+		})
+		for _, index := range animation.Frames {
+			grouped[index] = true
+			order = append(order, index)
+		}
+	}
+	for i := range sheet.Frames {
+		if !grouped[i] {
+			order = append(order, i)
+		}
+	}
+
+	frames := make([]aseFrame, 0, len(order))
+	slices := []aseSlice{}
+	for n, i := range order {
+		frame := sheet.Frames[i]
+		sourceW, sourceH := frame.SourceSize()
+		w, h := frame.Width(), frame.Height()
+		duration := frame.Duration
+		if duration <= 0 {
+			duration = opts.Duration
+		}
+		frames = append(frames, aseFrame{
+			Filename:         names[i],
+			Frame:            tpRect{X: frame.Rect.LT.X, Y: frame.Rect.LT.Y, W: w, H: h},
+			Rotated:          frame.Rotated(),
+			Trimmed:          frame.Trimmed,
+			SpriteSourceSize: tpRect{X: frame.OffsetX, Y: frame.OffsetY, W: w, H: h},
+			SourceSize:       tpSize{W: sourceW, H: sourceH},
+			Duration:         duration,
+		})
+		if frame.Pivot != nil {
+			slices = append(slices, aseSlice{
+				Name:  trimImageExt(names[i]),
+				Color: "#0000ffff",
+				Keys: []aseSliceKey{{
+					Frame:  n,
+					Bounds: tpRect{W: sourceW, H: sourceH},
+					Pivot: &aseXY{
+						X: int(math.Round(frame.Pivot.X * float64(sourceW))),
+						Y: int(math.Round(frame.Pivot.Y * float64(sourceH))),
+					},
+				}},
+			})
+		}
+	}
+	if tags == nil {
+		tags = []aseFrameTag{}
+	}
+
+	meta := aseMeta{
+		tpMeta: tpMeta{
+			App:     "sprite-cuter",
+			Version: "1.0",
+			Image:   sheet.Image,
+			Format:  "RGBA8888",
+			Size:    tpSize{W: sheet.Width, H: sheet.Height},
+			Scale:   "1",
+		},
+		FrameTags: tags,
+		Layers:    []aseLayer{{Name: "Layer 1", Opacity: 255, BlendMode: "normal"}},
+		Slices:    slices,
+	}
+
+	var v any
+	if opts.Array {
+		v = struct {
+			Frames []aseFrame `json:"frames"`
+			Meta   aseMeta    `json:"meta"`
+		}{frames, meta}
+	} else {
+		v = struct {
+			Frames aseHashFrames `json:"frames"`
+			Meta   aseMeta       `json:"meta"`
+		}{frames, meta}
+	}
+
+	// 结构中只有字符串、数值和布尔值，编码不会失败
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data), nil
+}
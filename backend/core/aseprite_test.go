@@ -0,0 +1,62 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAsepriteJsonRoundTrip(t *testing.T) {
+	want := atlasFrames()
+	want[1].Duration = 80
+	sheet := testSheet(32, 8, want...)
+	for _, array := range []bool{false, true} {
+		data, err := GetAsepriteJson(sheet, AsepriteOptions{Array: array, Duration: 120})
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames, err := ParseAtlas("sheet.json", []byte(data))
+		if err != nil {
+			t.Fatalf("array=%v: %v", array, err)
+		}
+		assertFrames(t, frames, want)
+
+		// 动画的帧按帧号连续排在前面，未指定时长的帧使用默认时长
+		order := []string{"walk_1.png", "walk_2.png", "idle.png", "jump.png"}
+		durations := []int{80, 120, 120, 120}
+		for i, frame := range frames {
+			if frame.Name != order[i] || frame.Duration != durations[i] {
+				t.Errorf("array=%v: 第%d帧为%s %dms, want %s %dms", array, i, frame.Name, frame.Duration, order[i], durations[i])
+			}
+		}
+	}
+}
+
+func TestAsepriteJsonTagsAndSlices(t *testing.T) {
+	sheet := testSheet(32, 8, atlasFrames()...)
+	data, err := GetAsepriteJson(sheet, AsepriteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Meta struct {
+			FrameTags []aseFrameTag `json:"frameTags"`
+			Slices    []aseSlice    `json:"slices"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Meta.FrameTags) != 1 || doc.Meta.FrameTags[0] != (aseFrameTag{Name: "walk", From: 0, To: 1, Direction: "forward", Color: "#000000ff"}) {
+		t.Errorf("frameTags = %+v", doc.Meta.FrameTags)
+	}
+	if len(doc.Meta.Slices) != 1 || doc.Meta.Slices[0].Name != "jump" || *doc.Meta.Slices[0].Keys[0].Pivot != (aseXY{X: 2, Y: 6}) {
+		t.Errorf("slices = %+v", doc.Meta.Slices)
+	}
+}
+
+func TestAsepriteJsonRejectsCCW(t *testing.T) {
+	if _, err := GetAsepriteJson(rotatedCCW(), AsepriteOptions{}); !errors.Is(err, ErrUnsupportedFrame) {
+		t.Errorf("err = %v, want ErrUnsupportedFrame", err)
+	}
+}
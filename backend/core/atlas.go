@@ -0,0 +1,497 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAtlas 解析已有的图集描述文件，返回其中记录的帧
+// 支持TexturePacker JSON（hash和array）、Cocos2d plist和LibGDX .atlas，
+// 按扩展名识别格式，无法识别时根据内容判断
+func ParseAtlas(filename string, data []byte) ([]Frame, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return parseJsonAtlas(trimmed)
+	case ".plist":
+		return parseCocosPlist(trimmed)
+	case ".atlas":
+		return parseLibGDXAtlas(trimmed)
+	}
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return parseJsonAtlas(trimmed)
+	case bytes.Contains(trimmed, []byte("<plist")):
+		return parseCocosPlist(trimmed)
+	}
+	return parseLibGDXAtlas(trimmed)
+}
+
+// tpRect TexturePacker JSON中的矩形
+type tpRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// tpFrame TexturePacker JSON中的帧
+type tpFrame struct {
+	Filename         string `json:"filename"`
+	Frame            tpRect `json:"frame"`
+	Rotated          bool   `json:"rotated"`
+	Trimmed          bool   `json:"trimmed"`
+	SpriteSourceSize tpRect `json:"spriteSourceSize"`
+	SourceSize       tpRect `json:"sourceSize"`
+	Pivot            *Pivot `json:"pivot"`
+	Duration         int    `json:"duration"` // Aseprite导出的帧时长
+}
+
+// parseJsonAtlas 解析JSON图集，包括TexturePacker、Aseprite格式和本工具导出的格式
+func parseJsonAtlas(data []byte) ([]Frame, error) {
+	var doc struct {
+		Version json.RawMessage `json:"version"`
+		Frames  json.RawMessage `json:"frames"`
+		Sprite  json.RawMessage `json:"sprite"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != nil || (doc.Frames == nil && doc.Sprite != nil) {
+		return ParseJson(data)
+	}
+	if doc.Frames == nil {
+		return nil, errors.New("JSON中没有frames字段")
+	}
+
+	var frames []tpFrame
+	raw := bytes.TrimSpace(doc.Frames)
+	if bytes.HasPrefix(raw, []byte("[")) {
+		// array格式
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, err
+		}
+	} else {
+		// hash格式，按文件中的顺序读取
+		names, values, err := decodeOrderedObject(raw)
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			var frame tpFrame
+			if err := json.Unmarshal(value, &frame); err != nil {
+				return nil, fmt.Errorf("帧 %s: %v", names[i], err)
+			}
+			frame.Filename = names[i]
+			frames = append(frames, frame)
+		}
+	}
+
+	result := make([]Frame, 0, len(frames))
+	for _, f := range frames {
+		frame := Frame{
+			Name:     f.Filename,
+			Rect:     NewRect(f.Frame.X, f.Frame.Y, f.Frame.W, f.Frame.H),
+			Trimmed:  f.Trimmed,
+			SourceW:  f.SourceSize.W,
+			SourceH:  f.SourceSize.H,
+			OffsetX:  f.SpriteSourceSize.X,
+			OffsetY:  f.SpriteSourceSize.Y,
+			Pivot:    f.Pivot,
+			Duration: f.Duration,
+		}
+		// 旋转帧的frame宽高为旋转前的尺寸，在图集中顺时针旋转存放
+		if f.Rotated {
+			frame.Rect = NewRect(f.Frame.X, f.Frame.Y, f.Frame.H, f.Frame.W)
+			frame.Rotation = 90
+		}
+		result = append(result, frame)
+	}
+	return result, nil
+}
+
+// decodeOrderedObject 按顺序解码JSON对象的键和值
+func decodeOrderedObject(data []byte) ([]string, []json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, errors.New("frames必须是对象或数组")
+	}
+
+	var names []string
+	var values []json.RawMessage
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		names = append(names, token.(string))
+		values = append(values, value)
+	}
+	return names, values, nil
+}
+
+// parseCocosPlist 解析Cocos2d plist图集，支持format 0-3
+func parseCocosPlist(data []byte) ([]Frame, error) {
+	root, err := parsePlist(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := root.(map[string]any)
+	if !ok {
+		return nil, errors.New("plist根节点必须是dict")
+	}
+	framesDict, ok := doc["frames"].(map[string]any)
+	if !ok {
+		return nil, errors.New("plist中没有frames字段")
+	}
+
+	format := int64(0)
+	if metadata, ok := doc["metadata"].(map[string]any); ok {
+		if f, ok := metadata["format"].(int64); ok {
+			format = f
+		}
+	}
+
+	// plist的dict没有顺序，按名称排序保证结果稳定
+	names := make([]string, 0, len(framesDict))
+	for name := range framesDict {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]Frame, 0, len(names))
+	for _, name := range names {
+		dict, ok := framesDict[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("帧 %s 格式错误", name)
+		}
+		frame, err := cocosFrame(name, dict, format)
+		if err != nil {
+			return nil, fmt.Errorf("帧 %s: %v", name, err)
+		}
+		result = append(result, frame)
+	}
+	return result, nil
+}
+
+// cocosFrame 解析Cocos2d plist中的单个帧
+func cocosFrame(name string, dict map[string]any, format int64) (Frame, error) {
+	var x, y, w, h, srcW, srcH int
+	var offX, offY float64
+	rotated := false
+	colorRect := false
+	var colorX, colorY int
+
+	switch format {
+	case 0:
+		x, y = plistInt(dict["x"]), plistInt(dict["y"])
+		w, h = plistInt(dict["width"]), plistInt(dict["height"])
+		offX, offY = plistFloat(dict["offsetX"]), plistFloat(dict["offsetY"])
+		srcW, srcH = plistInt(dict["originalWidth"]), plistInt(dict["originalHeight"])
+	case 1, 2:
+		rect, err := parseBraceNumbers(dict["frame"], 4)
+		if err != nil {
+			return Frame{}, err
+		}
+		x, y, w, h = int(rect[0]), int(rect[1]), int(rect[2]), int(rect[3])
+		if offset, err := parseBraceNumbers(dict["offset"], 2); err == nil {
+			offX, offY = offset[0], offset[1]
+		}
+		if size, err := parseBraceNumbers(dict["sourceSize"], 2); err == nil {
+			srcW, srcH = int(size[0]), int(size[1])
+		}
+		if r, err := parseBraceNumbers(dict["sourceColorRect"], 4); err == nil {
+			colorRect = true
+			colorX, colorY = int(r[0]), int(r[1])
+		}
+		rotated, _ = dict["rotated"].(bool)
+	case 3:
+		rect, err := parseBraceNumbers(dict["textureRect"], 4)
+		if err != nil {
+			return Frame{}, err
+		}
+		x, y, w, h = int(rect[0]), int(rect[1]), int(rect[2]), int(rect[3])
+		if offset, err := parseBraceNumbers(dict["spriteOffset"], 2); err == nil {
+			offX, offY = offset[0], offset[1]
+		}
+		if size, err := parseBraceNumbers(dict["spriteSourceSize"], 2); err == nil {
+			srcW, srcH = int(size[0]), int(size[1])
+		}
+		rotated, _ = dict["textureRotated"].(bool)
+	default:
+		return Frame{}, fmt.Errorf("不支持的plist格式: %d", format)
+	}
+
+	if srcW <= 0 || srcH <= 0 {
+		srcW, srcH = w, h
+	}
+
+	frame := Frame{
+		Name:    name,
+		Rect:    NewRect(x, y, w, h),
+		SourceW: srcW,
+		SourceH: srcH,
+		Trimmed: w != srcW || h != srcH,
+	}
+	if colorRect {
+		frame.OffsetX, frame.OffsetY = colorX, colorY
+	} else {
+		// offset为裁剪区域中心相对原图中心的偏移，y轴向上
+		frame.OffsetX = int((float64(srcW-w))/2 + offX)
+		frame.OffsetY = int((float64(srcH-h))/2 - offY)
+	}
+	// 旋转帧的宽高为旋转前的尺寸，在图集中顺时针旋转存放
+	if rotated {
+		frame.Rect = NewRect(x, y, h, w)
+		frame.Rotation = 90
+	}
+	return frame, nil
+}
+
+// parseBraceNumbers 解析 {{x,y},{w,h}} 形式的数字
+func parseBraceNumbers(value any, count int) ([]float64, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("缺少字段")
+	}
+	s = strings.NewReplacer("{", "", "}", "").Replace(s)
+	parts := strings.Split(s, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("格式错误: %s", value)
+	}
+	nums := make([]float64, count)
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("格式错误: %s", value)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// plistInt 将plist数值转换为整数
+func plistInt(value any) int {
+	return int(plistFloat(value))
+}
+
+// plistFloat 将plist数值转换为浮点数
+func plistFloat(value any) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f
+	}
+	return 0
+}
+
+// parseLibGDXAtlas 解析LibGDX .atlas文本格式，每次只切割一张图集图片，多页的atlas只读取第一页
+func parseLibGDXAtlas(data []byte) ([]Frame, error) {
+	pages, err := ParseLibGDXPages(data)
+	if err != nil {
+		return nil, err
+	}
+	return pages[0].Frames, nil
+}
+
+// ParseLibGDXPages 解析LibGDX .atlas文本格式，兼容新旧两种写法，每页纹理返回一个图集
+func ParseLibGDXPages(data []byte) ([]Sheet, error) {
+	var pages []Sheet
+	var current *Frame
+	var index = -1
+	var origW, origH, offX, offY int
+	var hasOrig bool
+	inPage := false
+	expectPage := true
+
+	// flush 结束当前区域
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		w, h := current.Rect.RT.X-current.Rect.LT.X, current.Rect.RB.Y-current.Rect.RT.Y
+		if !hasOrig {
+			origW, origH = w, h
+		}
+		current.SourceW, current.SourceH = origW, origH
+		current.Trimmed = w != origW || h != origH
+		// offset从原图左下角计算
+		current.OffsetX = offX
+		current.OffsetY = origH - h - offY
+		// 旋转区域的size为旋转前的尺寸，在图集中逆时针旋转存放
+		if current.Rotation != 0 {
+			x, y := current.Rect.LT.X, current.Rect.LT.Y
+			current.Rect = NewRect(x, y, h, w)
+		}
+		if index >= 0 {
+			current.Name = fmt.Sprintf("%s_%d", current.Name, index)
+		}
+		page := &pages[len(pages)-1]
+		page.Frames = append(page.Frames, *current)
+		current = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			expectPage = true
+			inPage = false
+			continue
+		}
+
+		key, value, isField := strings.Cut(trimmed, ":")
+		if isField {
+			key = strings.TrimSpace(key)
+			value = strings.TrimSpace(value)
+		}
+
+		// 页头：空行后的第一行为纹理文件名
+		if expectPage {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			pages = append(pages, Sheet{Image: trimmed})
+			expectPage = false
+			inPage = true
+			continue
+		}
+
+		if !isField {
+			// 新的区域
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			inPage = false
+			current = &Frame{Name: trimmed}
+			index = -1
+			hasOrig = false
+			origW, origH, offX, offY = 0, 0, 0, 0
+			continue
+		}
+
+		nums := func(count int) ([]int, error) {
+			parts := strings.Split(value, ",")
+			if len(parts) != count {
+				return nil, fmt.Errorf("第 %d 行格式错误: %s", lineNo, line)
+			}
+			result := make([]int, count)
+			for i, part := range parts {
+				n, err := strconv.Atoi(strings.TrimSpace(part))
+				if err != nil {
+					return nil, fmt.Errorf("第 %d 行格式错误: %s", lineNo, line)
+				}
+				result[i] = n
+			}
+			return result, nil
+		}
+
+		// 页头字段，只读取纹理尺寸
+		if inPage || current == nil {
+			if inPage && key == "size" {
+				v, err := nums(2)
+				if err != nil {
+					return nil, err
+				}
+				page := &pages[len(pages)-1]
+				page.Width, page.Height = v[0], v[1]
+			}
+			continue
+		}
+
+		switch key {
+		case "rotate":
+			switch value {
+			case "true", "90":
+				current.Rotation = 270
+			case "270":
+				current.Rotation = 90
+			case "false", "0":
+				current.Rotation = 0
+			default:
+				return nil, fmt.Errorf("第 %d 行不支持的旋转角度: %s", lineNo, value)
+			}
+		case "xy":
+			v, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			w, h := current.Rect.RT.X-current.Rect.LT.X, current.Rect.RB.Y-current.Rect.RT.Y
+			current.Rect = NewRect(v[0], v[1], w, h)
+		case "size":
+			v, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			current.Rect = NewRect(current.Rect.LT.X, current.Rect.LT.Y, v[0], v[1])
+		case "bounds":
+			v, err := nums(4)
+			if err != nil {
+				return nil, err
+			}
+			current.Rect = NewRect(v[0], v[1], v[2], v[3])
+		case "orig":
+			v, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			origW, origH, hasOrig = v[0], v[1], true
+		case "offset":
+			v, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			offX, offY = v[0], v[1]
+		case "offsets":
+			v, err := nums(4)
+			if err != nil {
+				return nil, err
+			}
+			offX, offY = v[0], v[1]
+			origW, origH, hasOrig = v[2], v[3], true
+		case "index":
+			v, err := nums(1)
+			if err != nil {
+				return nil, err
+			}
+			index = v[0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, errors.New("atlas中没有纹理页")
+	}
+	return pages, nil
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// cocosPlist 生成只包含frames和metadata的Cocos2d plist
+func cocosPlist(format int, frames string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>frames</key>
+	<dict>` + frames + `</dict>
+	<key>metadata</key>
+	<dict>
+		<key>format</key>
+		<integer>` + strconv.Itoa(format) + `</integer>
+	</dict>
+</dict>
+</plist>`
+}
+
+func TestParseAtlasTexturePackerArray(t *testing.T) {
+	data := `{"frames": [
+		{"filename": "b.png", "frame": {"x": 0, "y": 0, "w": 4, "h": 6}, "rotated": true, "trimmed": false,
+		 "spriteSourceSize": {"x": 0, "y": 0, "w": 4, "h": 6}, "sourceSize": {"w": 4, "h": 6}},
+		{"filename": "a.png", "frame": {"x": 6, "y": 0, "w": 2, "h": 2}, "rotated": false, "trimmed": true,
+		 "spriteSourceSize": {"x": 1, "y": 2, "w": 2, "h": 2}, "sourceSize": {"w": 5, "h": 5}}
+	]}`
+	frames, err := ParseAtlas("sheet.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Frame{
+		{Name: "b.png", Rect: NewRect(0, 0, 6, 4), Rotation: 90, SourceW: 4, SourceH: 6},
+		{Name: "a.png", Rect: NewRect(6, 0, 2, 2), Trimmed: true, SourceW: 5, SourceH: 5, OffsetX: 1, OffsetY: 2},
+	}
+	if !reflect.DeepEqual(frames, want) {
+		t.Errorf("ParseAtlas = %+v, want %+v", frames, want)
+	}
+}
+
+func TestParseAtlasKeepsHashOrder(t *testing.T) {
+	data := `{"frames": {"z.png": {"frame": {"x": 0, "y": 0, "w": 1, "h": 1}}, "a.png": {"frame": {"x": 1, "y": 0, "w": 1, "h": 1}}}}`
+	frames, err := ParseAtlas("sheet.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Name != "z.png" || frames[1].Name != "a.png" {
+		t.Errorf("帧顺序 = %+v, want z.png a.png", frames)
+	}
+}
+
+func TestParseAtlasDetectsContent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"带BOM的JSON", "\xef\xbb\xbf" + `{"frames": [{"filename": "a", "frame": {"x": 0, "y": 0, "w": 1, "h": 1}}]}`},
+		{"plist", cocosPlist(2, `<key>a</key><dict><key>frame</key><string>{{0,0},{1,1}}</string></dict>`)},
+		{"LibGDX", "sheet.png\nsize: 1,1\nformat: RGBA8888\nfilter: Nearest,Nearest\nrepeat: none\na\n  rotate: false\n  xy: 0, 0\n  size: 1, 1\n  orig: 1, 1\n  offset: 0, 0\n  index: -1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, err := ParseAtlas("upload.txt", []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(frames) != 1 || frames[0].Name != "a" || frames[0].Rect != NewRect(0, 0, 1, 1) {
+				t.Errorf("ParseAtlas = %+v", frames)
+			}
+		})
+	}
+}
+
+func TestParseAtlasErrors(t *testing.T) {
+	tests := map[string]string{
+		"a.json":  `{"meta": {}}`,
+		"b.json":  `{"frames": "x"}`,
+		"c.plist": `<plist><array></array></plist>`,
+		"d.plist": cocosPlist(2, `<key>a</key><dict><key>frame</key><string>{{0,0},{1}}</string></dict>`),
+		"e.plist": cocosPlist(4, `<key>a</key><dict></dict>`),
+	}
+	for name, data := range tests {
+		if _, err := ParseAtlas(name, []byte(data)); err == nil {
+			t.Errorf("%s 应返回错误", name)
+		}
+	}
+}
+
+func TestParseCocosPlistFormats(t *testing.T) {
+	tests := []struct {
+		format int
+		frame  string
+		want   Frame
+	}{
+		{0, `<key>x</key><integer>1</integer><key>y</key><integer>2</integer>
+			<key>width</key><integer>3</integer><key>height</key><integer>4</integer>
+			<key>offsetX</key><real>0</real><key>offsetY</key><real>0</real>
+			<key>originalWidth</key><integer>5</integer><key>originalHeight</key><integer>6</integer>`,
+			Frame{Name: "a", Rect: NewRect(1, 2, 3, 4), Trimmed: true, SourceW: 5, SourceH: 6, OffsetX: 1, OffsetY: 1}},
+		// offset的y轴向上
+		{2, `<key>frame</key><string>{{10,20},{6,4}}</string><key>offset</key><string>{1,-1}</string>
+			<key>rotated</key><false/><key>sourceSize</key><string>{10,8}</string>`,
+			Frame{Name: "a", Rect: NewRect(10, 20, 6, 4), Trimmed: true, SourceW: 10, SourceH: 8, OffsetX: 3, OffsetY: 3}},
+		{2, `<key>frame</key><string>{{0,0},{4,6}}</string><key>rotated</key><true/>
+			<key>sourceColorRect</key><string>{{2,1},{4,6}}</string><key>sourceSize</key><string>{8,8}</string>`,
+			Frame{Name: "a", Rect: NewRect(0, 0, 6, 4), Rotation: 90, Trimmed: true, SourceW: 8, SourceH: 8, OffsetX: 2, OffsetY: 1}},
+		{3, `<key>textureRect</key><string>{{0,0},{4,6}}</string><key>spriteOffset</key><string>{0,0}</string>
+			<key>spriteSourceSize</key><string>{4,6}</string><key>textureRotated</key><true/>`,
+			Frame{Name: "a", Rect: NewRect(0, 0, 6, 4), Rotation: 90, SourceW: 4, SourceH: 6}},
+	}
+	for _, tt := range tests {
+		frames, err := ParseAtlas("sheet.plist", []byte(cocosPlist(tt.format, `<key>a</key><dict>`+tt.frame+`</dict>`)))
+		if err != nil {
+			t.Errorf("format %d: %v", tt.format, err)
+			continue
+		}
+		if len(frames) != 1 || !reflect.DeepEqual(frames[0], tt.want) {
+			t.Errorf("format %d: %+v, want %+v", tt.format, frames, tt.want)
+		}
+	}
+}
+
+func TestParsePlistValues(t *testing.T) {
+	data := `<plist version="1.0"><dict>
+		<key>s</key><string> text </string>
+		<key>i</key><integer>-3</integer>
+		<key>r</key><real>1.5</real>
+		<key>t</key><true/>
+		<key>a</key><array><integer>1</integer><false/></array>
+	</dict></plist>`
+	got, err := parsePlist([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"s": "text", "i": int64(-3), "r": 1.5, "t": true, "a": []any{int64(1), false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePlist = %#v, want %#v", got, want)
+	}
+
+	for _, bad := range []string{`<plist></plist>`, `<plist><dict><string>x</string></dict></plist>`, `<plist><foo/></plist>`} {
+		if _, err := parsePlist([]byte(bad)); err == nil {
+			t.Errorf("%s 应返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AngelCode BMFont 导出格式
+const BMFont = "bmfont"
+
+// BMFontOptions BMFont 导出选项
+type BMFontOptions struct {
+	Chars    string `json:"chars"`    // 按阅读顺序与字形对应的字符序列，空白字符不占用字形
+	Face     string `json:"face"`     // 字体名称，默认使用图集名称
+	Baseline *int   `json:"baseline"` // 基线到每行字形顶部的距离，默认取该行最常见的字形底边
+	Spacing  *int   `json:"spacing"`  // 字符间距，默认1
+	Space    int    `json:"space"`    // 空格的宽度，默认为字形的平均宽度
+}
+
+// Validate 校验BMFont选项
+func (o BMFontOptions) Validate() error {
+	if strings.TrimSpace(o.Chars) == "" {
+		return errors.New("生成BMFont需要提供字符序列")
+	}
+	if !utf8.ValidString(o.Chars) {
+		return errors.New("字符序列不是有效的UTF-8")
+	}
+	if o.Baseline != nil && *o.Baseline < 0 {
+		return errors.New("基线不能为负数")
+	}
+	if (o.Spacing != nil && *o.Spacing < 0) || o.Space < 0 {
+		return errors.New("字符间距和空格宽度不能为负数")
+	}
+	return nil
+}
+
+// withDefaults 补全默认选项
+func (o BMFontOptions) withDefaults() BMFontOptions {
+	if o.Spacing == nil {
+		spacing := 1
+		o.Spacing = &spacing
+	}
+	return o
+}
+
+// bmChar BMFont中的一个字符
+type bmChar struct {
+	ID, X, Y, Width, Height, XOffset, YOffset, XAdvance int
+}
+
+// bmFont 生成BMFont所需的字体数据
+type bmFont struct {
+	Face       string
+	Size       int
+	LineHeight int
+	Base       int
+	Padding    [4]int // 字形区域中四周的透明边：上、右、下、左
+	Spacing    [2]int // 图集中字形之间的最小间隔：水平、垂直
+	Chars      []bmChar
+}
+
+// GetBMFont 按阅读顺序将字符序列对应到图集中的字形，生成AngelCode BMFont
+// 返回文本格式和XML格式的.fnt，图集本身作为唯一的page。
+// 字形按行分组，同一行的字形共用基线，基线和字形的度量按区域中不透明像素的范围计算：
+// xoffset为不透明像素左侧的透明边取负值，使字形紧贴光标，yoffset为区域顶部按基线对齐后的位置，
+// xadvance为不透明像素的宽度加上字符间距。info中的padding为所有字形四周共有的透明边，
+// spacing为图集中相邻字形的最小间隔。检测时会忽略宽高不超过3像素的精灵，
+// 包含这类字形的字体需要按网格切割
+func GetBMFont(sheet Sheet, baseName string, opts BMFontOptions) (text, xml string, err error) {
+	if err := opts.Validate(); err != nil {
+		return "", "", err
+	}
+	font, err := buildBMFont(sheet, baseName, opts)
+	if err != nil {
+		return "", "", err
+	}
+	return font.text(sheet), font.xml(sheet), nil
+}
+
+// buildBMFont 计算字符的位置和度量
+func buildBMFont(sheet Sheet, baseName string, opts BMFontOptions) (bmFont, error) {
+	lines := readingOrder(sheet.Frames)
+	glyphCount := 0
+	for _, line := range lines {
+		glyphCount += len(line)
+	}
+	var runes []rune
+	for _, r := range opts.Chars {
+		if !unicode.IsSpace(r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) != glyphCount {
+		return bmFont{}, fmt.Errorf("字符序列中有 %d 个字符，图集中有 %d 个字形", len(runes), glyphCount)
+	}
+	opts = opts.withDefaults()
+	spacing := *opts.Spacing
+
+	// 字形中不透明像素的范围
+	ink := make([]Rect, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		ink[i] = glyphBounds(sheet, frame.Rect)
+	}
+
+	// 每行的基线，base为基线到行顶部的最大距离，descent为基线以下的最大高度
+	type lineMetrics struct{ top, baseline int }
+	metrics := make([]lineMetrics, len(lines))
+	base, descent := 0, 0
+	for n, line := range lines {
+		top, bottom := ink[line[0]].LT.Y, 0
+		counts := map[int]int{}
+		for _, i := range line {
+			top = min(top, ink[i].LT.Y)
+			bottom = max(bottom, ink[i].RB.Y)
+			counts[ink[i].RB.Y]++
+		}
+		baseline := 0
+		if opts.Baseline != nil {
+			baseline = top + *opts.Baseline
+		} else {
+			// 取最常见的字形底边，避免下伸部分影响基线
+			for y, count := range counts {
+				if count > counts[baseline] || (count == counts[baseline] && y > baseline) {
+					baseline = y
+				}
+			}
+		}
+		metrics[n] = lineMetrics{top, baseline}
+		base = max(base, baseline-top)
+		descent = max(descent, bottom-baseline)
+	}
+
+	font := bmFont{Face: opts.Face, Base: base, LineHeight: base + descent}
+	if font.Face == "" {
+		font.Face = baseName
+	}
+	font.Size = font.LineHeight
+	font.Padding, font.Spacing = glyphPadding(sheet.Frames, ink), glyphSpacing(sheet.Frames, lines)
+
+	totalWidth := 0
+	seen := map[rune]bool{}
+	k := 0
+	for n, line := range lines {
+		for _, i := range line {
+			rect := sheet.Frames[i].Rect
+			r := runes[k]
+			k++
+			inkWidth := ink[i].RT.X - ink[i].LT.X
+			totalWidth += inkWidth
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			font.Chars = append(font.Chars, bmChar{
+				ID:       int(r),
+				X:        rect.LT.X,
+				Y:        rect.LT.Y,
+				Width:    rect.RT.X - rect.LT.X,
+				Height:   rect.RB.Y - rect.RT.Y,
+				XOffset:  rect.LT.X - ink[i].LT.X,
+				YOffset:  base - (metrics[n].baseline - rect.LT.Y),
+				XAdvance: inkWidth + spacing,
+			})
+		}
+	}
+
+	// 字符序列中包含空格时添加空格字符
+	if strings.ContainsRune(opts.Chars, ' ') {
+		space := opts.Space
+		if space <= 0 && glyphCount > 0 {
+			space = (totalWidth + glyphCount/2) / glyphCount
+		}
+		font.Chars = append(font.Chars, bmChar{ID: ' ', XAdvance: space + spacing})
+	}
+	sort.Slice(font.Chars, func(a, b int) bool { return font.Chars[a].ID < font.Chars[b].ID })
+	return font, nil
+}
+
+// glyphBounds 返回区域中不透明像素的外接矩形，没有图像或区域全透明时返回区域本身
+func glyphBounds(sheet Sheet, rect Rect) Rect {
+	if sheet.img == nil {
+		return rect
+	}
+	bounds := sheet.img.Bounds()
+	left, top, right, bottom := rect.RT.X, rect.RB.Y, rect.LT.X, rect.LT.Y
+	for y := max(rect.LT.Y, 0); y < min(rect.RB.Y, bounds.Dy()); y++ {
+		for x := max(rect.LT.X, 0); x < min(rect.RT.X, bounds.Dx()); x++ {
+			if alphaAt(sheet.img, bounds.Min.X+x, bounds.Min.Y+y) > 0 {
+				left, top = min(left, x), min(top, y)
+				right, bottom = max(right, x+1), max(bottom, y+1)
+			}
+		}
+	}
+	if left >= right || top >= bottom {
+		return rect
+	}
+	return NewRect(left, top, right-left, bottom-top)
+}
+
+// glyphPadding 返回所有字形四周共有的透明边，顺序为上、右、下、左
+func glyphPadding(frames []Frame, ink []Rect) [4]int {
+	var padding [4]int
+	for i, frame := range frames {
+		rect := frame.Rect
+		margins := [4]int{ink[i].LT.Y - rect.LT.Y, rect.RT.X - ink[i].RT.X, rect.RB.Y - ink[i].RB.Y, ink[i].LT.X - rect.LT.X}
+		for side, margin := range margins {
+			if i == 0 || margin < padding[side] {
+				padding[side] = margin
+			}
+		}
+	}
+	return padding
+}
+
+// glyphSpacing 返回图集中同一行相邻字形的最小水平间隔和相邻行的最小垂直间隔
+func glyphSpacing(frames []Frame, lines [][]int) [2]int {
+	horizontal, vertical := -1, -1
+	for n, line := range lines {
+		for k := 1; k < len(line); k++ {
+			gap := frames[line[k]].Rect.LT.X - frames[line[k-1]].Rect.RT.X
+			if horizontal < 0 || gap < horizontal {
+				horizontal = gap
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		bottom := 0
+		for _, i := range lines[n-1] {
+			bottom = max(bottom, frames[i].Rect.RB.Y)
+		}
+		for _, i := range line {
+			gap := frames[i].Rect.LT.Y - bottom
+			if vertical < 0 || gap < vertical {
+				vertical = gap
+			}
+		}
+	}
+	return [2]int{max(horizontal, 0), max(vertical, 0)}
+}
+
+// readingOrder 将帧按阅读顺序分行，行从上到下，行内从左到右
+// 与当前行在垂直方向上重叠的帧属于同一行
+func readingOrder(frames []Frame) [][]int {
+	order := make([]int, len(frames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return frames[order[a]].Rect.LT.Y < frames[order[b]].Rect.LT.Y
+	})
+
+	var lines [][]int
+	bottom := 0
+	for _, i := range order {
+		rect := frames[i].Rect
+		if len(lines) == 0 || rect.LT.Y >= bottom {
+			lines = append(lines, nil)
+			bottom = rect.RB.Y
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], i)
+		bottom = max(bottom, rect.RB.Y)
+	}
+	for _, line := range lines {
+		sort.SliceStable(line, func(a, b int) bool {
+			return frames[line[a]].Rect.LT.X < frames[line[b]].Rect.LT.X
+		})
+	}
+	return lines
+}
+
+// text 生成文本格式的.fnt
+func (f bmFont) text(sheet Sheet) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "info face=\"%s\" size=%d bold=0 italic=0 charset=\"\" unicode=1 stretchH=100 smooth=0 aa=1 padding=%s spacing=%s\n",
+		bmQuote(f.Face), f.Size, f.padding(), f.spacing())
+	fmt.Fprintf(&b, "common lineHeight=%d base=%d scaleW=%d scaleH=%d pages=1 packed=0\n", f.LineHeight, f.Base, sheet.Width, sheet.Height)
+	fmt.Fprintf(&b, "page id=0 file=\"%s\"\n", bmQuote(sheet.Image))
+	fmt.Fprintf(&b, "chars count=%d\n", len(f.Chars))
+	for _, c := range f.Chars {
+		fmt.Fprintf(&b, "char id=%-5d x=%-5d y=%-5d width=%-5d height=%-5d xoffset=%-5d yoffset=%-5d xadvance=%-5d page=0  chnl=15\n",
+			c.ID, c.X, c.Y, c.Width, c.Height, c.XOffset, c.YOffset, c.XAdvance)
+	}
+	return b.String()
+}
+
+// xml 生成XML格式的.fnt
+func (f bmFont) xml(sheet Sheet) string {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\"?>\n<font>\n")
+	fmt.Fprintf(&b, "  <info face=\"%s\" size=\"%d\" bold=\"0\" italic=\"0\" charset=\"\" unicode=\"1\" stretchH=\"100\" smooth=\"0\" aa=\"1\" padding=\"%s\" spacing=\"%s\" outline=\"0\"/>\n",
+		xmlEscape(f.Face), f.Size, f.padding(), f.spacing())
+	fmt.Fprintf(&b, "  <common lineHeight=\"%d\" base=\"%d\" scaleW=\"%d\" scaleH=\"%d\" pages=\"1\" packed=\"0\"/>\n", f.LineHeight, f.Base, sheet.Width, sheet.Height)
+	fmt.Fprintf(&b, "  <pages>\n    <page id=\"0\" file=\"%s\"/>\n  </pages>\n", xmlEscape(sheet.Image))
+	fmt.Fprintf(&b, "  <chars count=\"%d\">\n", len(f.Chars))
+	for _, c := range f.Chars {
+		fmt.Fprintf(&b, "    <char id=\"%d\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" xoffset=\"%d\" yoffset=\"%d\" xadvance=\"%d\" page=\"0\" chnl=\"15\"/>\n",
+			c.ID, c.X, c.Y, c.Width, c.Height, c.XOffset, c.YOffset, c.XAdvance)
+	}
+	b.WriteString("  </chars>\n</font>\n")
+	return b.String()
+}
+
+// padding 返回info中的padding
+func (f bmFont) padding() string {
+	return fmt.Sprintf("%d,%d,%d,%d", f.Padding[0], f.Padding[1], f.Padding[2], f.Padding[3])
+}
+
+// spacing 返回info中的spacing
+func (f bmFont) spacing() string {
+	return fmt.Sprintf("%d,%d", f.Spacing[0], f.Spacing[1])
+}
+
+// bmQuote 文本格式的.fnt不支持转义，去除字符串中的双引号和换行
+func bmQuote(s string) string {
+	return strings.NewReplacer(`"`, "", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fntChars 解析文本格式.fnt中的字符，键为字符
+func fntChars(t *testing.T, text string) map[rune]map[string]int {
+	t.Helper()
+	chars := map[rune]map[string]int{}
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "char ") {
+			continue
+		}
+		fields := map[string]int{}
+		for _, m := range regexp.MustCompile(`(\w+)=(-?\d+)`).FindAllStringSubmatch(line, -1) {
+			n, _ := strconv.Atoi(m[2])
+			fields[m[1]] = n
+		}
+		chars[rune(fields["id"])] = fields
+	}
+	return chars
+}
+
+// fntInfo 返回文本格式.fnt中info行的字段
+func fntInfo(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
+
+func TestBMFontDetectedGlyphs(t *testing.T) {
+	// 紧贴字形的区域，相邻字形间隔2像素，C带下伸部分
+	sheet := testSheet(32, 16,
+		Frame{Rect: NewRect(0, 0, 4, 6)},
+		Frame{Rect: NewRect(6, 1, 5, 5)},
+		Frame{Rect: NewRect(13, 2, 3, 7)},
+	)
+	text, fntXML, err := GetBMFont(sheet, "font", BMFontOptions{Chars: "A B C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := fntInfo(text); !strings.Contains(info, "padding=0,0,0,0 spacing=2,0") {
+		t.Errorf("info = %s", info)
+	}
+	if !strings.Contains(text, "common lineHeight=9 base=6 ") {
+		t.Errorf("common错误:\n%s", text)
+	}
+
+	chars := fntChars(t, text)
+	want := map[rune][3]int{ // xoffset, yoffset, xadvance
+		'A': {0, 0, 5},
+		'B': {0, 1, 6},
+		'C': {0, 2, 4},
+	}
+	for r, w := range want {
+		c := chars[r]
+		if c["xoffset"] != w[0] || c["yoffset"] != w[1] || c["xadvance"] != w[2] {
+			t.Errorf("%c: xoffset=%d yoffset=%d xadvance=%d, want %v", r, c["xoffset"], c["yoffset"], c["xadvance"], w)
+		}
+	}
+	// 空格宽度为字形的平均宽度
+	if chars[' ']["xadvance"] != 5 {
+		t.Errorf("空格 xadvance = %d", chars[' ']["xadvance"])
+	}
+
+	var doc struct {
+		Chars []struct {
+			ID int `xml:"id,attr"`
+		} `xml:"chars>char"`
+	}
+	if err := xml.Unmarshal([]byte(fntXML), &doc); err != nil || len(doc.Chars) != 4 {
+		t.Errorf("XML格式错误: %v %+v", err, doc)
+	}
+}
+
+func TestBMFontExplicitZeroSpacing(t *testing.T) {
+	sheet := testSheet(16, 8, Frame{Rect: NewRect(0, 0, 4, 6)}, Frame{Rect: NewRect(6, 0, 5, 6)})
+	e, _ := LookupExporter(BMFont)
+	files, err := e.Export(ExportContext{Sheet: sheet, BaseName: "font"}, []byte(`{"chars": "AB", "spacing": 0}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chars := fntChars(t, files[0].Content)
+	if chars['A']["xadvance"] != 4 || chars['B']["xadvance"] != 5 {
+		t.Errorf("spacing为0时 xadvance = %d, %d", chars['A']["xadvance"], chars['B']["xadvance"])
+	}
+
+	// 未指定时默认间距为1
+	files, err = e.Export(ExportContext{Sheet: sheet, BaseName: "font"}, []byte(`{"chars": "AB"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chars := fntChars(t, files[0].Content); chars['A']["xadvance"] != 5 {
+		t.Errorf("默认 xadvance = %d", chars['A']["xadvance"])
+	}
+}
+
+func TestBMFontGridCells(t *testing.T) {
+	// 按网格切割的8x8单元格，字形位于单元格内不同位置
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 8))
+	fill := func(x, y, w, h int) {
+		for j := y; j < y+h; j++ {
+			for i := x; i < x+w; i++ {
+				img.Set(i, j, color.NRGBA{A: 255})
+			}
+		}
+	}
+	fill(2, 1, 4, 6)  // 左右各2像素、上下各1像素的透明边
+	fill(11, 1, 3, 5) // 左3右2、上1下2
+	sheet := NewSheet("font.png", img, FramesFromRects([]Rect{NewRect(0, 0, 8, 8), NewRect(8, 0, 8, 8)}))
+
+	text, _, err := GetBMFont(sheet, "font", BMFontOptions{Chars: "ab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := fntInfo(text); !strings.Contains(info, "padding=1,2,1,2 spacing=0,0") {
+		t.Errorf("info = %s", info)
+	}
+	chars := fntChars(t, text)
+	if a := chars['a']; a["xoffset"] != -2 || a["xadvance"] != 5 || a["width"] != 8 {
+		t.Errorf("a = %v", a)
+	}
+	if b := chars['b']; b["xoffset"] != -3 || b["xadvance"] != 4 {
+		t.Errorf("b = %v", b)
+	}
+}
+
+func TestBMFontCharCountMismatch(t *testing.T) {
+	sheet := testSheet(16, 8, Frame{Rect: NewRect(0, 0, 4, 6)})
+	if _, _, err := GetBMFont(sheet, "font", BMFontOptions{Chars: "AB"}); err == nil {
+		t.Error("字符数与字形数不一致时应返回错误")
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// Cocos2d plist 导出格式
+const Cocos2dPlist = "cocos2d-plist"
+
+// plistWriter 生成XML格式plist
+type plistWriter struct {
+	buf    bytes.Buffer
+	indent int
+}
+
+// line 写入一行缩进后的内容
+func (w *plistWriter) line(format string, args ...any) {
+	for i := 0; i < w.indent; i++ {
+		w.buf.WriteByte('\t')
+	}
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteByte('\n')
+}
+
+// key 写入dict的键
+func (w *plistWriter) key(name string) {
+	w.line("<key>%s</key>", xmlEscape(name))
+}
+
+// str 写入字符串值
+func (w *plistWriter) str(name, value string) {
+	w.key(name)
+	w.line("<string>%s</string>", xmlEscape(value))
+}
+
+// boolean 写入布尔值
+func (w *plistWriter) boolean(name string, value bool) {
+	w.key(name)
+	if value {
+		w.line("<true/>")
+	} else {
+		w.line("<false/>")
+	}
+}
+
+// integer 写入整数值
+func (w *plistWriter) integer(name string, value int) {
+	w.key(name)
+	w.line("<integer>%d</integer>", value)
+}
+
+// open 开始一个dict或array
+func (w *plistWriter) open(tag string) {
+	w.line("<%s>", tag)
+	w.indent++
+}
+
+// close 结束一个dict或array
+func (w *plistWriter) close(tag string) {
+	w.indent--
+	w.line("</%s>", tag)
+}
+
+// GetCocos2dPlist 生成Cocos2d-x使用的plist图集（format 3），重复的帧名称加上序号
+// Cocos2d只能表示顺时针旋转存放的帧，有逆时针旋转存放的帧时返回错误
+func GetCocos2dPlist(sheet Sheet) (string, error) {
+	if err := checkClockwise(sheet.Frames); err != nil {
+		return "", err
+	}
+	names := frameNames(sheet.Frames)
+	w := &plistWriter{}
+	w.line(`<?xml version="1.0" encoding="UTF-8"?>`)
+	w.line(`<!DOCTYPE plist PUBLIC "-//Apple Computer//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">`)
+	w.line(`<plist version="1.0">`)
+	w.open("dict")
+
+	w.key("frames")
+	w.open("dict")
+	for i, frame := range sheet.Frames {
+		sourceW, sourceH := frame.SourceSize()
+		width, height := frame.Width(), frame.Height()
+		// spriteOffset为裁剪区域中心相对原图中心的偏移，y轴向上
+		offsetX := float64(frame.OffsetX) + float64(width)/2 - float64(sourceW)/2
+		offsetY := float64(sourceH)/2 - float64(frame.OffsetY) - float64(height)/2
+
+		w.key(names[i])
+		w.open("dict")
+		w.key("aliases")
+		w.line("<array/>")
+		w.str("spriteOffset", fmt.Sprintf("{%s,%s}", formatFloat(offsetX), formatFloat(offsetY)))
+		w.str("spriteSize", fmt.Sprintf("{%d,%d}", width, height))
+		w.str("spriteSourceSize", fmt.Sprintf("{%d,%d}", sourceW, sourceH))
+		// textureRect的宽高为旋转前的尺寸
+		w.str("textureRect", fmt.Sprintf("{{%d,%d},{%d,%d}}", frame.Rect.LT.X, frame.Rect.LT.Y, width, height))
+		w.boolean("textureRotated", frame.Rotated())
+		w.close("dict")
+	}
+	w.close("dict")
+
+	w.key("metadata")
+	w.open("dict")
+	w.integer("format", 3)
+	w.str("pixelFormat", "RGBA8888")
+	w.boolean("premultiplyAlpha", false)
+	w.str("realTextureFileName", sheet.Image)
+	w.str("size", fmt.Sprintf("{%d,%d}", sheet.Width, sheet.Height))
+	w.str("textureFileName", sheet.Image)
+	w.close("dict")
+
+	w.close("dict")
+	w.line("</plist>")
+	return w.buf.String(), nil
+}
+
+// xmlEscape 转义XML文本和属性值
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	// 写入bytes.Buffer不会失败
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// formatFloat 格式化浮点数，整数时不带小数部分
+func formatFloat(v float64) string {
+	return fmt.Sprintf("%g", v)
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestCocos2dPlistRoundTrip(t *testing.T) {
+	sheet := testSheet(32, 8, atlasFrames()...)
+	data, err := GetCocos2dPlist(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := ParseAtlas("sheet.plist", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFrames(t, frames, atlasFrames())
+}
+
+func TestCocos2dPlistDuplicateNames(t *testing.T) {
+	sheet := testSheet(16, 8,
+		Frame{Name: "a.png", Rect: NewRect(0, 0, 4, 4)},
+		Frame{Name: "a.png", Rect: NewRect(8, 0, 4, 4)},
+	)
+	data, err := GetCocos2dPlist(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := ParseAtlas("sheet.plist", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("重复名称的帧被合并: %+v", frames)
+	}
+}
+
+func TestCocos2dPlistEscapesNames(t *testing.T) {
+	sheet := testSheet(8, 8, Frame{Name: "<a&b>", Rect: NewRect(0, 0, 4, 4)})
+	data, err := GetCocos2dPlist(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := ParseAtlas("sheet.plist", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 1 || frames[0].Name != "<a&b>" {
+		t.Errorf("帧名称 = %+v", frames)
+	}
+}
@@ -0,0 +1,257 @@
+package core
+
+import (
+	"image"
+	"math"
+)
+
+// Outline 一个不透明区域的外轮廓及其中的孔洞
+type Outline struct {
+	Outer []Point   // 外轮廓
+	Holes [][]Point // 区域内被完全包围的透明部分的轮廓
+}
+
+// FrameContours 检测帧中每个不透明区域的外轮廓
+// 轮廓坐标为帧原始尺寸中的像素角点坐标，tolerance为简化轮廓时允许的最大偏差（像素），
+// 小于等于0时只去除共线的点
+func FrameContours(img image.Image, frame Frame, tolerance float64) [][]Point {
+	return imageContours(frameImage(img, frame), tolerance)
+}
+
+// FrameOutlines 检测帧中每个不透明区域的外轮廓和孔洞，坐标和tolerance同FrameContours
+func FrameOutlines(img image.Image, frame Frame, tolerance float64) []Outline {
+	return imageOutlines(frameImage(img, frame), tolerance, true)
+}
+
+// neighbors4 4邻域的偏移
+var neighbors4 = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// neighbors8 8邻域的偏移
+var neighbors8 = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// imageContours 检测图像中每个不透明区域（4邻域连通）的外轮廓
+func imageContours(img image.Image, tolerance float64) [][]Point {
+	var contours [][]Point
+	for _, outline := range imageOutlines(img, tolerance, false) {
+		contours = append(contours, outline.Outer)
+	}
+	return contours
+}
+
+// imageOutlines 检测图像中每个不透明区域的轮廓，holes为true时同时追踪孔洞
+// marchingSquares每次只追踪一条4邻域连通的边界，区域按4邻域划分，只有角点相接的像素分属不同区域
+func imageOutlines(img image.Image, tolerance float64, holes bool) []Outline {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	data := alphaData(img)
+	component := make([]uint8, len(data))
+
+	var outlines []Outline
+	var stack, pixels []int
+	for start := 0; start < width*height; start++ {
+		if data[start*4+3] == 0 {
+			continue
+		}
+
+		// 取出起始像素所在的连通区域
+		pixels = pixels[:0]
+		stack = append(stack[:0], start)
+		data[start*4+3] = 0
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			pixels = append(pixels, p)
+			px, py := p%width, p/width
+			for _, d := range neighbors4 {
+				nx, ny := px+d[0], py+d[1]
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+				n := ny*width + nx
+				if data[n*4+3] != 0 {
+					data[n*4+3] = 0
+					stack = append(stack, n)
+				}
+			}
+		}
+
+		// 只在该区域上追踪轮廓
+		for _, p := range pixels {
+			component[p*4+3] = 255
+		}
+		outline := Outline{Outer: simplifyContour(marchingSquares(component, height, width), tolerance)}
+		if holes && len(outline.Outer) >= 3 {
+			outline.Holes = componentHoles(component, width, height, pixels, tolerance)
+		}
+		for _, p := range pixels {
+			component[p*4+3] = 0
+		}
+		if len(outline.Outer) >= 3 {
+			outlines = append(outlines, outline)
+		}
+	}
+	return outlines
+}
+
+// componentHoles 追踪连通区域中孔洞的轮廓
+// 孔洞为区域外接矩形内、与矩形外部不8邻域连通的其他像素（包括孔洞中的其他区域）。
+// 外轮廓会绕进只在角点与外部相接的透明像素，这些像素不算孔洞；
+// 每个孔洞按4邻域划分后追踪，与marchingSquares一致。
+// component中该区域的像素为不透明，pixels为该区域的像素下标
+func componentHoles(component []uint8, width, height int, pixels []int, tolerance float64) [][]Point {
+	minX, minY, maxX, maxY := width, height, -1, -1
+	for _, p := range pixels {
+		minX, maxX = min(minX, p%width), max(maxX, p%width)
+		minY, maxY = min(minY, p/width), max(maxY, p/width)
+	}
+
+	// 在向外扩展一圈的矩形中从边缘填充外部，剩下未填充的非区域像素即为孔洞
+	boxW, boxH := maxX-minX+3, maxY-minY+3
+	inside := func(bx, by int) bool {
+		x, y := minX+bx-1, minY+by-1
+		return x >= 0 && y >= 0 && x < width && y < height && component[(y*width+x)*4+3] != 0
+	}
+	visited := make([]bool, boxW*boxH)
+	fill := func(start int, out []int, neighbors [][2]int) []int {
+		stack := []int{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			b := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			out = append(out, b)
+			bx, by := b%boxW, b/boxW
+			for _, d := range neighbors {
+				nx, ny := bx+d[0], by+d[1]
+				if nx < 0 || ny < 0 || nx >= boxW || ny >= boxH {
+					continue
+				}
+				n := ny*boxW + nx
+				if !visited[n] && !inside(nx, ny) {
+					visited[n] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+		return out
+	}
+	fill(0, nil, neighbors8)
+
+	// 在矩形范围内逐个追踪孔洞，坐标换算回图像
+	var holes [][]Point
+	var mask []uint8
+	for b := range visited {
+		if visited[b] || inside(b%boxW, b/boxW) {
+			continue
+		}
+		if mask == nil {
+			mask = make([]uint8, boxW*boxH*4)
+		}
+		region := fill(b, nil, neighbors4)
+		for _, r := range region {
+			mask[r*4+3] = 255
+		}
+		contour := simplifyContour(marchingSquares(mask, boxH, boxW), tolerance)
+		for _, r := range region {
+			mask[r*4+3] = 0
+		}
+		if len(contour) < 3 {
+			continue
+		}
+		for i := range contour {
+			contour[i].X += minX - 1
+			contour[i].Y += minY - 1
+		}
+		holes = append(holes, contour)
+	}
+	return holes
+}
+
+// simplifyContour 简化闭合轮廓：去除重复和共线的点，再按Douglas-Peucker算法简化
+func simplifyContour(points []Point, tolerance float64) []Point {
+	// 去除重复和共线的点
+	var result []Point
+	for _, p := range points {
+		if n := len(result); n > 0 && result[n-1] == p {
+			continue
+		}
+		if n := len(result); n >= 2 && cross(result[n-2], result[n-1], p) == 0 {
+			result[n-1] = p
+			continue
+		}
+		result = append(result, p)
+	}
+	for len(result) >= 3 {
+		n := len(result)
+		if result[0] == result[n-1] {
+			result = result[:n-1]
+		} else if cross(result[n-2], result[n-1], result[0]) == 0 {
+			result = result[:n-1]
+		} else if cross(result[n-1], result[0], result[1]) == 0 {
+			result = result[1:]
+		} else {
+			break
+		}
+	}
+	if tolerance <= 0 || len(result) < 4 {
+		return result
+	}
+
+	// 从第一个点和离它最远的点把轮廓分成两段分别简化
+	far := 0
+	for i, p := range result {
+		if distSq(result[0], p) > distSq(result[0], result[far]) {
+			far = i
+		}
+	}
+	closed := append(append([]Point{}, result...), result[0])
+	first := douglasPeucker(closed[:far+1], tolerance)
+	second := douglasPeucker(closed[far:], tolerance)
+	simplified := append(first, second[1:len(second)-1]...)
+	if len(simplified) < 3 {
+		return result
+	}
+	return simplified
+}
+
+// douglasPeucker 按Douglas-Peucker算法简化折线，保留首尾两点
+func douglasPeucker(points []Point, tolerance float64) []Point {
+	if len(points) < 3 {
+		return points
+	}
+	first, last := points[0], points[len(points)-1]
+	index, maxDist := 0, 0.0
+	for i := 1; i < len(points)-1; i++ {
+		if d := segmentDistance(points[i], first, last); d > maxDist {
+			index, maxDist = i, d
+		}
+	}
+	if maxDist <= tolerance {
+		return []Point{first, last}
+	}
+	left := douglasPeucker(points[:index+1], tolerance)
+	right := douglasPeucker(points[index:], tolerance)
+	return append(left[:len(left)-1], right...)
+}
+
+// cross 返回向量ab与ac的叉积
+func cross(a, b, c Point) int {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// distSq 返回两点距离的平方
+func distSq(a, b Point) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx*dx + dy*dy
+}
+
+// segmentDistance 返回点p到线段ab的距离
+func segmentDistance(p, a, b Point) float64 {
+	if a == b {
+		return math.Sqrt(float64(distSq(p, a)))
+	}
+	dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+	t := (float64(p.X-a.X)*dx + float64(p.Y-a.Y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	x, y := float64(a.X)+t*dx, float64(a.Y)+t*dy
+	return math.Hypot(float64(p.X)-x, float64(p.Y)-y)
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// maskImage 按字符画生成图像，#为不透明像素
+func maskImage(rows ...string) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				img.Set(x, y, color.NRGBA{A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestImageOutlinesWithHole(t *testing.T) {
+	img := maskImage(
+		"......",
+		".####.",
+		".#..#.",
+		".#..#.",
+		".####.",
+		"......",
+	)
+	outlines := imageOutlines(img, 0, true)
+	if len(outlines) != 1 {
+		t.Fatalf("轮廓数 = %d, want 1", len(outlines))
+	}
+	outer := outlines[0].Outer
+	if len(outer) != 4 || polygonArea(outer) != 32 {
+		t.Errorf("外轮廓 = %v, want 4x4的矩形", outer)
+	}
+	for _, p := range outer {
+		if p.X != 1 && p.X != 5 || p.Y != 1 && p.Y != 5 {
+			t.Errorf("外轮廓顶点 %v 不在像素角点上", p)
+		}
+	}
+	if len(outlines[0].Holes) != 1 || polygonArea(outlines[0].Holes[0]) != 8 {
+		t.Errorf("孔洞 = %v, want 一个2x2的孔洞", outlines[0].Holes)
+	}
+	// 不追踪孔洞时只返回外轮廓
+	if contours := imageContours(img, 0); len(contours) != 1 || polygonArea(contours[0]) != 32 {
+		t.Errorf("imageContours = %v", contours)
+	}
+}
+
+func TestImageOutlinesIslandInHole(t *testing.T) {
+	img := maskImage(
+		"#####",
+		"#...#",
+		"#.#.#",
+		"#...#",
+		"#####",
+	)
+	outlines := imageOutlines(img, 0, true)
+	if len(outlines) != 2 {
+		t.Fatalf("轮廓数 = %d, want 外框和孔洞中的小岛", len(outlines))
+	}
+	areas := map[int]int{}
+	for _, outline := range outlines {
+		areas[polygonArea(outline.Outer)] = len(outline.Holes)
+	}
+	// polygonArea为面积的两倍；孔洞包括其中的小岛，小岛本身没有孔洞
+	if holes, ok := areas[50]; !ok || holes != 1 {
+		t.Errorf("外框 = %v", areas)
+	}
+	if holes, ok := areas[2]; !ok || holes != 0 {
+		t.Errorf("小岛 = %v", areas)
+	}
+}
+
+func TestImageOutlinesConnectivity(t *testing.T) {
+	// 只有角点相接的像素各自成为一个区域
+	diagonal := maskImage(
+		"#...",
+		".#..",
+		"...#",
+	)
+	if outlines := imageOutlines(diagonal, 0, true); len(outlines) != 3 {
+		t.Errorf("轮廓数 = %d, want 3", len(outlines))
+	}
+}
+
+func TestImageOutlinesArea(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"角点相接的方块", maskImage(
+			"##..",
+			"##..",
+			"..##",
+			"..##",
+		)},
+		{"对角缺口的环", maskImage(
+			"###.",
+			"#.#.",
+			"##..",
+		)},
+		{"左上角缺口的环", maskImage(
+			"..##",
+			".#.#",
+			".###",
+		)},
+		{"右上角缺口的环", maskImage(
+			"##..",
+			"#.#.",
+			"###.",
+		)},
+		{"左下角缺口的环", maskImage(
+			".###",
+			".#.#",
+			"..##",
+		)},
+		{"对角相接的孔洞", maskImage(
+			"####",
+			"#.##",
+			"##.#",
+			"####",
+		)},
+		{"带孔洞的环", maskImage(
+			"####",
+			"#..#",
+			"####",
+		)},
+		{"孔洞中的区域", maskImage(
+			"#####",
+			"#...#",
+			"#.#.#",
+			"#...#",
+			"#####",
+		)},
+	}
+	for _, tt := range tests {
+		// 轮廓面积减去孔洞面积应等于不透明像素数
+		want := 0
+		bounds := tt.img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if _, _, _, a := tt.img.At(x, y).RGBA(); a != 0 {
+					want += 2
+				}
+			}
+		}
+		got := 0
+		for _, outline := range imageOutlines(tt.img, 0, true) {
+			got += polygonArea(outline.Outer)
+			for _, hole := range outline.Holes {
+				got -= polygonArea(hole)
+			}
+		}
+		if got != want {
+			t.Errorf("%s: 面积的两倍 = %d, want %d", tt.name, got, want)
+		}
+	}
+}
+
+func TestSimplifyContour(t *testing.T) {
+	// 阶梯状的边在容差内合并为斜边
+	img := maskImage(
+		"#...",
+		"##..",
+		"###.",
+		"####",
+	)
+	exact := imageContours(img, 0)
+	simple := imageContours(img, 1)
+	if len(exact) != 1 || len(simple) != 1 {
+		t.Fatalf("轮廓数 = %d, %d", len(exact), len(simple))
+	}
+	if len(simple[0]) >= len(exact[0]) || len(simple[0]) < 3 {
+		t.Errorf("简化后 %d 个点, 简化前 %d 个点", len(simple[0]), len(exact[0]))
+	}
+	if polygonArea(exact[0]) != 20 {
+		t.Errorf("未简化的面积的两倍 = %d, want 20", polygonArea(exact[0]))
+	}
+}
+
+func TestFrameContoursRestoresFrame(t *testing.T) {
+	// 旋转存放并去除透明边的帧，轮廓坐标为原始尺寸中的坐标
+	img := maskImage(
+		"###",
+		"###",
+	)
+	frame := Frame{Rect: NewRect(0, 0, 3, 2), Rotation: 90, Trimmed: true, SourceW: 4, SourceH: 5, OffsetX: 1, OffsetY: 1}
+	contours := FrameContours(img, frame, 0)
+	if len(contours) != 1 || polygonArea(contours[0]) != 12 {
+		t.Fatalf("轮廓 = %v", contours)
+	}
+	for _, p := range contours[0] {
+		if p.X < 1 || p.X > 3 || p.Y < 1 || p.Y > 4 {
+			t.Errorf("顶点 %v 超出还原后的区域", p)
+		}
+	}
+}
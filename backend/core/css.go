@@ -0,0 +1,170 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CSSOptions CSS导出选项，SCSS、Less等样式导出沿用同样的命名规则
+type CSSOptions struct {
+	Prefix     string `json:"prefix"`      // 公共类名，默认sprite
+	Template   string `json:"template"`    // 精灵类名模板，可用{prefix}、{name}、{index}，默认{name}
+	Image      string `json:"image"`       // 图集的url，默认使用图集文件名
+	Retina     bool   `json:"retina"`      // 同时引用2倍图集，输出image-set和媒体查询
+	RetinaFile string `json:"retina_file"` // 已上传的2倍图集，为空时将图集按最近邻放大2倍，不会增加细节
+	Variables  bool   `json:"variables"`   // 为每个精灵输出CSS自定义属性
+	Minify     bool   `json:"minify"`      // 压缩输出，否则按规则换行缩进
+}
+
+// Validate 校验CSS选项
+func (o CSSOptions) Validate() error {
+	if o.Template != "" && !strings.Contains(o.Template, "{name}") && !strings.Contains(o.Template, "{index}") {
+		return errors.New("类名模板中必须包含{name}或{index}")
+	}
+	return nil
+}
+
+// defaultCSSOptions 返回默认的CSS选项
+func defaultCSSOptions() CSSOptions {
+	return CSSOptions{Prefix: "sprite", Template: "{name}"}
+}
+
+// BaseClass 返回所有精灵共用的类名
+func (o CSSOptions) BaseClass() string {
+	if o.Prefix == "" {
+		return "sprite"
+	}
+	return cssClassName(o.Prefix)
+}
+
+// ClassName 按类名模板返回帧的类名
+func (o CSSOptions) ClassName(index int, frame Frame) string {
+	template := o.Template
+	if template == "" {
+		template = "{name}"
+	}
+	name := strings.NewReplacer(
+		"{prefix}", o.BaseClass(),
+		"{name}", trimImageExt(FrameName(index, frame)),
+		"{index}", strconv.Itoa(index),
+	).Replace(template)
+	return cssClassName(name)
+}
+
+// AnimationClass 返回动画的类名，同时用作@keyframes的名称
+// 使用{prefix}-anim-{name}，与公共类名和精灵的类名区分开
+func (o CSSOptions) AnimationClass(name string) string {
+	return cssClassName(o.BaseClass() + "-anim-" + trimImageExt(name))
+}
+
+// RetinaImage 返回2倍图集的文件名，如 sheet.png 对应 sheet@2x.png
+func RetinaImage(image string) string {
+	ext := ""
+	if i := strings.LastIndex(image, "."); i > strings.LastIndex(image, "/") {
+		image, ext = image[:i], image[i:]
+	}
+	return image + "@2x" + ext
+}
+
+// cssWriter 按是否压缩输出CSS规则
+type cssWriter struct {
+	b      strings.Builder
+	minify bool
+	indent string
+}
+
+// rule 写入一条规则
+func (w *cssWriter) rule(selector string, decls ...string) {
+	if w.minify {
+		compact := make([]string, len(decls))
+		for i, decl := range decls {
+			compact[i] = strings.Replace(decl, ": ", ":", 1)
+		}
+		fmt.Fprintf(&w.b, "%s{%s}", selector, strings.Join(compact, ";"))
+		return
+	}
+	fmt.Fprintf(&w.b, "%s%s {\n", w.indent, selector)
+	for _, decl := range decls {
+		fmt.Fprintf(&w.b, "%s  %s;\n", w.indent, decl)
+	}
+	fmt.Fprintf(&w.b, "%s}\n", w.indent)
+}
+
+// open 开始一个@规则块
+func (w *cssWriter) open(prelude string) {
+	if w.minify {
+		w.b.WriteString(prelude + "{")
+		return
+	}
+	w.b.WriteString(prelude + " {\n")
+	w.indent = "  "
+}
+
+// close 结束@规则块
+func (w *cssWriter) close() {
+	w.indent = ""
+	if w.minify {
+		w.b.WriteString("}")
+		return
+	}
+	w.b.WriteString("}\n")
+}
+
+// GetCSS 生成CSS样式，基于内置模板templates/css.tmpl
+// 公共类设置图集背景，每个精灵一个类设置尺寸和背景位置，使用时同时添加两个类。
+// 开启Retina时background-size固定为图集尺寸，高分屏通过image-set或媒体查询使用2倍图集
+func GetCSS(sheet Sheet, opts CSSOptions) string {
+	css := executeBuiltin(cssTemplate, NewTemplateModel(sheet, "", opts))
+	if opts.Minify {
+		css = minifyCSS(css)
+	}
+	return css
+}
+
+// minifyCSS 压缩按规则换行缩进的CSS
+// 每行为一个选择器或@规则的开头、一条声明或一个右括号
+func minifyCSS(css string) string {
+	var b []byte
+	for _, line := range strings.Split(css, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case line == "}":
+			if n := len(b); n > 0 && b[n-1] == ';' {
+				b = b[:n-1]
+			}
+			b = append(b, '}')
+		case strings.HasSuffix(line, " {"):
+			b = append(b, strings.TrimSuffix(line, " {")+"{"...)
+		default:
+			b = append(b, strings.Replace(line, ": ", ":", 1)...)
+		}
+	}
+	return string(b)
+}
+
+// cssString 返回带引号的CSS字符串
+func cssString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\a `)
+	return `"` + r.Replace(s) + `"`
+}
+
+// cssClassName 将帧名称转换为合法的CSS类名
+func cssClassName(name string) string {
+	var b strings.Builder
+	for _, r := range trimImageExt(name) {
+		if r == '-' || r == '_' || r >= 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	class := b.String()
+	if class == "" || unicode.IsDigit(rune(class[0])) {
+		class = "_" + class
+	}
+	return class
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestCSSClassName(t *testing.T) {
+	tests := map[string]string{
+		"walk_1.png":  "walk_1",
+		"run left":    "run-left",
+		"2x/coin.png": "_2x-coin",
+		"":            "_",
+		"角色.png":      "角色",
+	}
+	for name, want := range tests {
+		if got := cssClassName(name); got != want {
+			t.Errorf("cssClassName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCSSOptionsClassName(t *testing.T) {
+	frame := Frame{Name: "coin.png"}
+	opts := CSSOptions{Prefix: "ui icon", Template: "{prefix}-{name}-{index}"}
+	if got := opts.ClassName(3, frame); got != "ui-icon-coin-3" {
+		t.Errorf("ClassName = %q", got)
+	}
+	if got := (CSSOptions{}).ClassName(0, Frame{}); got != "sprite0" {
+		t.Errorf("未命名帧的类名 = %q", got)
+	}
+	if err := (CSSOptions{Template: "{prefix}"}).Validate(); err == nil {
+		t.Error("不含{name}或{index}的模板应返回错误")
+	}
+}
+
+func TestGetCSS(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	css := GetCSS(sheet, CSSOptions{Prefix: "hero", Template: "{prefix}-{name}"})
+	for _, want := range []string{
+		".hero {\n",
+		"background-image: url(\"sheet.png\");\n",
+		".hero-walk_1 {\n  width: 6px;\n  height: 5px;\n  background-position: -8px 0px;\n}",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("缺少 %q:\n%s", want, css)
+		}
+	}
+	if strings.Contains(css, "image-set") || strings.Contains(css, "--sprite-x") {
+		t.Errorf("未开启retina和variables:\n%s", css)
+	}
+}
+
+func TestGetCSSRetinaAndVariables(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	css := GetCSS(sheet, CSSOptions{Retina: true, Variables: true, Image: "img/a b.png"})
+	for _, want := range []string{
+		`image-set(url("img/a b.png") 1x, url("img/a b@2x.png") 2x)`,
+		"background-size: 32px 16px;",
+		"@media (-webkit-min-device-pixel-ratio: 2), (min-resolution: 192dpi)",
+		"--sprite-x: -8px;",
+		"background-position: var(--sprite-x) var(--sprite-y);",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("缺少 %q:\n%s", want, css)
+		}
+	}
+}
+
+func TestGetCSSMinify(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	css := GetCSS(sheet, CSSOptions{Retina: true, Minify: true})
+	if strings.ContainsAny(css, "\n") || strings.Contains(css, ";}") {
+		t.Errorf("压缩输出格式错误:\n%s", css)
+	}
+	if !strings.Contains(css, ".idle{width:8px;height:8px;background-position:0px 0px}") {
+		t.Errorf("压缩输出缺少规则:\n%s", css)
+	}
+	if strings.Count(css, "{") != strings.Count(css, "}") {
+		t.Errorf("括号不匹配:\n%s", css)
+	}
+}
+
+func TestRetinaImage(t *testing.T) {
+	tests := map[string]string{"sheet.png": "sheet@2x.png", "a.b/sheet": "a.b/sheet@2x", "x": "x@2x"}
+	for image, want := range tests {
+		if got := RetinaImage(image); got != want {
+			t.Errorf("RetinaImage(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestRetinaSheet(t *testing.T) {
+	sheet := testSheet(8, 4, Frame{Rect: NewRect(0, 0, 4, 4)})
+	exportRetina := func(file string, size int) (image.Image, error) {
+		src := image.NewNRGBA(image.Rect(0, 0, size, size/2))
+		src.Set(1, 1, color.NRGBA{G: 255, A: 255})
+		var encoded bytes.Buffer
+		png.Encode(&encoded, src)
+		ctx := ExportContext{
+			Sheet:    sheet,
+			BaseName: "hero",
+			CSS:      CSSOptions{Retina: true, RetinaFile: file},
+			ReadFile: func(name string) ([]byte, string, error) { return encoded.Bytes(), name, nil },
+		}
+		e, _ := LookupExporter(SheetFormat)
+		files, err := e.Export(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.Name == "hero@2x.png" {
+				return png.Decode(strings.NewReader(file.Content))
+			}
+		}
+		t.Fatal("缺少2倍图集")
+		return nil, nil
+	}
+
+	// 未指定时按最近邻放大图集
+	img, err := exportRetina("", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := img.At(1, 1).RGBA(); img.Bounds().Dx() != 16 || a == 0 {
+		t.Errorf("放大的2倍图集 %v", img.Bounds())
+	}
+	// 指定时使用上传的2倍图集
+	img, err = exportRetina("hero@2x.png", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, g, _, _ := img.At(1, 1).RGBA(); g == 0 {
+		t.Error("应使用上传的2倍图集")
+	}
+	if _, err := exportRetina("hero@2x.png", 12); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("尺寸不符: err = %v, want ErrInvalidSource", err)
+	}
+}
+
+func TestCSSString(t *testing.T) {
+	if got := cssString("a\"b\\c\n"); got != `"a\"b\\c\a "` {
+		t.Errorf("cssString = %s", got)
+	}
+}
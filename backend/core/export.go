@@ -0,0 +1,158 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"image"
+)
+
+// JSONVersion 导出JSON的格式版本号
+const JSONVersion = 1
+
+// JSONSchema 导出JSON对应的JSON Schema
+//
+//go:embed schema/sheet.schema.json
+var JSONSchema []byte
+
+// Sheet 表示一张待导出的图集
+type Sheet struct {
+	Image  string       // 图集图片文件名
+	Width  int          // 图集宽度
+	Height int          // 图集高度
+	Frames []Frame      // 图集中的帧
+	Grid   *GridOptions // 按网格切割时的网格参数
+
+	img image.Image // 图集图像，用于需要像素数据的导出格式
+}
+
+// NewSheet 根据图集图片和帧创建图集
+func NewSheet(imageName string, img image.Image, frames []Frame) Sheet {
+	bounds := img.Bounds()
+	return Sheet{
+		Image:  imageName,
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Frames: frames,
+		img:    img,
+	}
+}
+
+// sheetJSON 导出JSON的结构
+type sheetJSON struct {
+	Version int         `json:"version"`
+	Image   string      `json:"image"`
+	Width   int         `json:"width"`
+	Height  int         `json:"height"`
+	Frames  []frameJSON `json:"frames"`
+}
+
+// frameJSON 导出JSON中的帧
+type frameJSON struct {
+	Name         string `json:"name"`
+	X            int    `json:"x"`
+	Y            int    `json:"y"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Rotation     int    `json:"rotation,omitempty"`
+	Trimmed      bool   `json:"trimmed,omitempty"`
+	SourceWidth  int    `json:"sourceWidth"`
+	SourceHeight int    `json:"sourceHeight"`
+	OffsetX      int    `json:"offsetX"`
+	OffsetY      int    `json:"offsetY"`
+
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// legacySheetJSON 旧版JSON的结构，坐标沿用CSS background-position的符号
+type legacySheetJSON struct {
+	Sprite struct {
+		Width  int               `json:"width"`
+		Height int               `json:"height"`
+		Image  string            `json:"image"`
+		Frames []legacyFrameJSON `json:"frames"`
+	} `json:"sprite"`
+}
+
+// legacyFrameJSON 旧版JSON中的帧
+type legacyFrameJSON struct {
+	Name   string `json:"name"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// JSONOptions JSON导出选项
+type JSONOptions struct {
+	Legacy bool `json:"legacy"` // 输出旧版结构
+}
+
+// GetJson 生成JSON，legacy为true时输出旧版结构，基于内置模板templates/json.tmpl
+func GetJson(sheet Sheet, legacy bool) string {
+	model := NewTemplateModel(sheet, "", CSSOptions{})
+	if legacy {
+		return executeBuiltin(legacyJSONTemplate, model)
+	}
+	return executeBuiltin(jsonTemplate, model)
+}
+
+// ParseJson 解析GetJson生成的JSON，兼容旧版结构，返回其中记录的帧
+func ParseJson(data []byte) ([]Frame, error) {
+	var probe struct {
+		Version *int            `json:"version"`
+		Sprite  json.RawMessage `json:"sprite"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	var frames []Frame
+	switch {
+	case probe.Version != nil:
+		if *probe.Version > JSONVersion {
+			return nil, errors.New("不支持的JSON版本")
+		}
+		var doc sheetJSON
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, f := range doc.Frames {
+			frames = append(frames, Frame{
+				Name:       f.Name,
+				Rect:       NewRect(f.X, f.Y, f.Width, f.Height),
+				Rotation:   f.Rotation,
+				Trimmed:    f.Trimmed,
+				SourceW:    f.SourceWidth,
+				SourceH:    f.SourceHeight,
+				OffsetX:    f.OffsetX,
+				OffsetY:    f.OffsetY,
+				Properties: f.Properties,
+			})
+		}
+	case probe.Sprite != nil:
+		var doc legacySheetJSON
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, f := range doc.Sprite.Frames {
+			// 坐标沿用CSS background-position的符号
+			frames = append(frames, Frame{
+				Name:    f.Name,
+				Rect:    NewRect(-f.X, -f.Y, f.Width, f.Height),
+				SourceW: f.Width,
+				SourceH: f.Height,
+			})
+		}
+	default:
+		return nil, errors.New("无法识别的JSON结构")
+	}
+
+	// 默认名称的帧保持未命名
+	for i := range frames {
+		if frames[i].Name == FrameName(i, Frame{}) {
+			frames[i].Name = ""
+		}
+	}
+	return frames, nil
+}
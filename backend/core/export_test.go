@@ -0,0 +1,120 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// jsonFrames JSON往返测试的帧，名称包含需要转义的字符
+func jsonFrames() []Frame {
+	return []Frame{
+		{Rect: NewRect(0, 0, 4, 4), SourceW: 4, SourceH: 4},
+		{Name: "say \"hi\"\\\n</script>", Rect: NewRect(4, 0, 6, 5), Trimmed: true, SourceW: 10, SourceH: 9, OffsetX: 3, OffsetY: 1},
+		{Name: "走路_1 ", Rect: NewRect(10, 0, 5, 7), Rotation: 90, SourceW: 7, SourceH: 5,
+			Properties: map[string]string{"tag": "a\"b", "z": "\t"}},
+	}
+}
+
+func TestJsonRoundTrip(t *testing.T) {
+	want := jsonFrames()
+	sheet := testSheet(16, 8, want...)
+	data := GetJson(sheet, false)
+
+	// 内置模板的输出与类型化的结构一致，不含多余的字段
+	var doc sheetJSON
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if doc.Version != JSONVersion || doc.Image != "sheet.png" || doc.Width != 16 || doc.Height != 8 {
+		t.Errorf("文件头 = %+v", doc)
+	}
+
+	frames, err := ParseJson([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(frames, want) {
+		t.Errorf("ParseJson = %+v\nwant %+v", frames, want)
+	}
+}
+
+func TestJsonEmptySheet(t *testing.T) {
+	data := GetJson(testSheet(4, 4), false)
+	var doc sheetJSON
+	if err := json.Unmarshal([]byte(data), &doc); err != nil || doc.Frames == nil || len(doc.Frames) != 0 {
+		t.Errorf("空图集: %v %+v\n%s", err, doc, data)
+	}
+}
+
+func TestJsonMatchesSchema(t *testing.T) {
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties struct {
+			Version struct {
+				Const int `json:"const"`
+			} `json:"version"`
+		} `json:"properties"`
+		Defs struct {
+			Frame struct {
+				Required   []string                   `json:"required"`
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"frame"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(JSONSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Properties.Version.Const != JSONVersion {
+		t.Errorf("schema中的版本号 %d 与JSONVersion %d 不一致", schema.Properties.Version.Const, JSONVersion)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(GetJson(testSheet(16, 8, jsonFrames()...), false)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range schema.Required {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("缺少必需字段 %s", key)
+		}
+	}
+	if doc["version"] != float64(schema.Properties.Version.Const) {
+		t.Errorf("version = %v", doc["version"])
+	}
+	for i, f := range doc["frames"].([]any) {
+		frame := f.(map[string]any)
+		for _, key := range schema.Defs.Frame.Required {
+			if _, ok := frame[key]; !ok {
+				t.Errorf("第%d帧缺少必需字段 %s", i, key)
+			}
+		}
+		for key := range frame {
+			if _, ok := schema.Defs.Frame.Properties[key]; !ok {
+				t.Errorf("第%d帧的字段 %s 不在schema中", i, key)
+			}
+		}
+	}
+}
+
+func TestLegacyJsonRoundTrip(t *testing.T) {
+	sheet := testSheet(16, 8, jsonFrames()...)
+	frames, err := ParseJson([]byte(GetJson(sheet, true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 旧版结构只记录名称和区域
+	for i, want := range jsonFrames() {
+		if frames[i].Name != want.Name || frames[i].Rect != want.Rect {
+			t.Errorf("第%d帧 = %+v, want %+v", i, frames[i], want)
+		}
+	}
+}
+
+func TestParseJsonRejectsNewerVersion(t *testing.T) {
+	if _, err := ParseJson([]byte(`{"version": 99, "frames": []}`)); err == nil {
+		t.Error("更新的版本应返回错误")
+	}
+}
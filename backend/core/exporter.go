@@ -0,0 +1,238 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ExportFile 导出的一个文件
+type ExportFile struct {
+	Name    string // 相对输出目录的路径
+	Content string
+}
+
+// FormatOption 导出格式的一个选项
+type FormatOption struct {
+	Name        string   `json:"name"`           // 选项在options中的键名
+	Type        string   `json:"type"`           // string、integer、number或boolean
+	Default     any      `json:"default"`        // 默认值，没有默认值时为null
+	Enum        []string `json:"enum,omitempty"` // 可选的取值
+	Description string   `json:"description"`
+}
+
+// FormatInfo 导出格式的说明
+type FormatInfo struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Extensions  []string       `json:"extensions"`         // 生成的文件后缀，第一个为主文件
+	Default     bool           `json:"default"`            // 未指定导出格式时是否导出
+	Requires    []string       `json:"requires,omitempty"` // 依赖的其他导出格式，如引用图集图片的格式依赖sheet
+	Options     []FormatOption `json:"options"`
+}
+
+// ErrInvalidSource 导出选项引用的文件内容无效，如尺寸不符的2倍图集
+var ErrInvalidSource = errors.New("引用的文件无效")
+
+// ExportContext 各导出格式共用的数据
+type ExportContext struct {
+	Sheet    Sheet       // 图集，Image为输出目录中的图集文件名
+	BaseName string      // 输出文件的基础名称
+	Name     string      // 图集的原始名称，不含上传时添加的随机后缀，用于生成重复导出时保持不变的标识
+	CSS      CSSOptions  // CSS命名规则，样式相关的格式共用css格式的选项
+	Save     SaveOptions // 保存图集和精灵图的选项
+	Result   *SaveResult // 累计精灵图的保存结果，可为nil
+
+	// ReadFile 读取选项中引用的文件，如用户模板，返回文件内容和原始文件名
+	ReadFile func(name string) ([]byte, string, error)
+
+	// Logf 记录不影响其余导出的错误，如某个精灵保存失败，可为nil
+	Logf func(format string, args ...any)
+}
+
+// sourceName 返回图集的原始名称，未设置时使用输出文件的基础名称
+func (ctx ExportContext) sourceName() string {
+	if ctx.Name != "" {
+		return ctx.Name
+	}
+	return ctx.BaseName
+}
+
+// Exporter 导出格式
+type Exporter interface {
+	// Info 返回导出格式的说明，选项的默认值已填入
+	Info() FormatInfo
+	// Validate 校验导出选项，options为空时使用默认选项
+	Validate(options json.RawMessage) error
+	// Export 按选项导出，返回需要写入输出目录的文件
+	Export(ctx ExportContext, options json.RawMessage) ([]ExportFile, error)
+}
+
+// NewExporter 创建选项类型为T的导出格式
+// defaults返回默认选项，请求中的选项覆盖在默认选项之上；T实现Validate() error时导出前校验选项
+func NewExporter[T any](info FormatInfo, defaults func() T, export func(ctx ExportContext, opts T) ([]ExportFile, error)) Exporter {
+	return &optionExporter[T]{info: info, defaults: defaults, export: export}
+}
+
+// optionExporter 由选项类型和导出函数组成的导出格式
+type optionExporter[T any] struct {
+	info     FormatInfo
+	defaults func() T
+	export   func(ctx ExportContext, opts T) ([]ExportFile, error)
+}
+
+func (e *optionExporter[T]) Info() FormatInfo {
+	info := e.info
+	info.Options = append([]FormatOption{}, info.Options...)
+
+	// 默认值取自默认选项的JSON
+	var defaults map[string]any
+	if data, err := json.Marshal(e.defaults()); err == nil {
+		json.Unmarshal(data, &defaults)
+	}
+	for i := range info.Options {
+		info.Options[i].Default = defaults[info.Options[i].Name]
+	}
+	return info
+}
+
+func (e *optionExporter[T]) Validate(options json.RawMessage) error {
+	_, err := e.options(options)
+	return err
+}
+
+func (e *optionExporter[T]) Export(ctx ExportContext, options json.RawMessage) ([]ExportFile, error) {
+	opts, err := e.options(options)
+	if err != nil {
+		return nil, err
+	}
+	return e.export(ctx, opts)
+}
+
+// options 解析并校验导出选项
+func (e *optionExporter[T]) options(options json.RawMessage) (T, error) {
+	opts := e.defaults()
+	if len(options) > 0 && string(options) != "null" {
+		decoder := json.NewDecoder(bytes.NewReader(options))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&opts); err != nil {
+			return opts, fmt.Errorf("%s的选项错误: %v", e.info.ID, err)
+		}
+	}
+	if v, ok := any(opts).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return opts, fmt.Errorf("%s的选项错误: %v", e.info.ID, err)
+		}
+	}
+	return opts, nil
+}
+
+// exporters 已注册的导出格式，exporterOrder记录注册顺序
+var (
+	exporters     = map[string]Exporter{}
+	exporterOrder []string
+)
+
+// RegisterExporter 注册导出格式，ID重复时panic
+func RegisterExporter(e Exporter) {
+	id := e.Info().ID
+	if _, ok := exporters[id]; ok {
+		panic("导出格式重复注册: " + id)
+	}
+	exporters[id] = e
+	exporterOrder = append(exporterOrder, id)
+}
+
+// LookupExporter 按ID查找导出格式
+func LookupExporter(id string) (Exporter, bool) {
+	e, ok := exporters[id]
+	return e, ok
+}
+
+// Formats 返回所有导出格式的说明，按注册顺序排列
+func Formats() []FormatInfo {
+	infos := make([]FormatInfo, 0, len(exporterOrder))
+	for _, id := range exporterOrder {
+		infos = append(infos, exporters[id].Info())
+	}
+	return infos
+}
+
+// FormatRequest 请求导出的格式及其选项
+// JSON中可以是格式ID字符串，也可以是 {"id": "...", "options": {...}}
+type FormatRequest struct {
+	ID      string          `json:"id"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// UnmarshalJSON 解析格式ID字符串或带选项的对象
+func (r *FormatRequest) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*r = FormatRequest{}
+		return json.Unmarshal(data, &r.ID)
+	}
+	type plain FormatRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// ResolveFormats 校验请求的导出格式及选项，并补全依赖的格式
+// 未指定任何格式时使用默认导出的格式，同一格式只能出现一次
+func ResolveFormats(requests []FormatRequest) ([]FormatRequest, error) {
+	if len(requests) == 0 {
+		for _, id := range exporterOrder {
+			if exporters[id].Info().Default {
+				requests = append(requests, FormatRequest{ID: id})
+			}
+		}
+	}
+
+	resolved := make([]FormatRequest, 0, len(requests))
+	seen := map[string]bool{}
+	for _, req := range requests {
+		e, ok := exporters[req.ID]
+		if !ok {
+			return nil, fmt.Errorf("不支持的导出格式: %s", req.ID)
+		}
+		if seen[req.ID] {
+			return nil, fmt.Errorf("导出格式重复: %s", req.ID)
+		}
+		if err := e.Validate(req.Options); err != nil {
+			return nil, err
+		}
+		seen[req.ID] = true
+		resolved = append(resolved, req)
+	}
+
+	// 依赖的格式使用默认选项，依赖的依赖同样补全
+	for i := 0; i < len(resolved); i++ {
+		for _, id := range exporters[resolved[i].ID].Info().Requires {
+			if !seen[id] {
+				seen[id] = true
+				resolved = append(resolved, FormatRequest{ID: id})
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// ResolveCSSOptions 返回请求中css格式的选项，未请求css格式时返回默认选项
+// 样式相关的格式按同样的规则命名，供填入ExportContext.CSS
+func ResolveCSSOptions(requests []FormatRequest) (CSSOptions, error) {
+	opts := defaultCSSOptions()
+	for _, req := range requests {
+		if req.ID != CSSFormat || len(req.Options) == 0 || string(req.Options) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(req.Options, &opts); err != nil {
+			return opts, fmt.Errorf("%s的选项错误: %v", CSSFormat, err)
+		}
+	}
+	return opts, opts.Validate()
+}
+
+// singleFile 返回只包含一个文件的导出结果
+func singleFile(name, content string) []ExportFile {
+	return []ExportFile{{Name: name, Content: content}}
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatIDs 返回请求中的格式ID
+func formatIDs(requests []FormatRequest) []string {
+	ids := make([]string, len(requests))
+	for i, req := range requests {
+		ids[i] = req.ID
+	}
+	return ids
+}
+
+func TestFormatRequestUnmarshal(t *testing.T) {
+	var requests []FormatRequest
+	data := `["css", {"id": "scss"}, {"id": "json", "options": {"legacy": true}}]`
+	if err := json.Unmarshal([]byte(data), &requests); err != nil {
+		t.Fatal(err)
+	}
+	if got := formatIDs(requests); !reflect.DeepEqual(got, []string{"css", "scss", "json"}) {
+		t.Errorf("ID = %v", got)
+	}
+	if requests[0].Options != nil || string(requests[2].Options) != `{"legacy": true}` {
+		t.Errorf("选项 = %s, %s", requests[0].Options, requests[2].Options)
+	}
+}
+
+func TestResolveFormats(t *testing.T) {
+	defaults, err := ResolveFormats(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	required := map[string]bool{}
+	for _, req := range defaults {
+		e, _ := LookupExporter(req.ID)
+		for _, id := range e.Info().Requires {
+			required[id] = true
+		}
+	}
+	for _, req := range defaults {
+		e, _ := LookupExporter(req.ID)
+		if !e.Info().Default && !required[req.ID] {
+			t.Errorf("%s 不是默认格式", req.ID)
+		}
+	}
+
+	// 依赖的格式及其依赖自动补全，且只出现一次
+	resolved, err := ResolveFormats([]FormatRequest{{ID: SCSS}, {ID: CSSFormat}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatIDs(resolved); !reflect.DeepEqual(got, []string{SCSS, CSSFormat, SheetFormat}) {
+		t.Errorf("ResolveFormats = %v", got)
+	}
+
+	for name, requests := range map[string][]FormatRequest{
+		"未知格式": {{ID: "psd"}},
+		"重复格式": {{ID: CSSFormat}, {ID: CSSFormat}},
+		"未知选项": {{ID: CSSFormat, Options: json.RawMessage(`{"colour": "red"}`)}},
+		"选项类型": {{ID: CSSFormat, Options: json.RawMessage(`{"retina": "yes"}`)}},
+		"选项校验": {{ID: CSSFormat, Options: json.RawMessage(`{"template": "{prefix}"}`)}},
+	} {
+		if _, err := ResolveFormats(requests); err == nil {
+			t.Errorf("%s 应返回错误", name)
+		}
+	}
+}
+
+func TestResolveCSSOptions(t *testing.T) {
+	opts, err := ResolveCSSOptions([]FormatRequest{{ID: SCSS}})
+	if err != nil || opts != defaultCSSOptions() {
+		t.Errorf("未请求css时 = %+v, %v", opts, err)
+	}
+	opts, err = ResolveCSSOptions([]FormatRequest{{ID: CSSFormat, Options: json.RawMessage(`{"prefix": "ui"}`)}})
+	if err != nil || opts.Prefix != "ui" || opts.Template != "{name}" {
+		t.Errorf("ResolveCSSOptions = %+v, %v", opts, err)
+	}
+}
+
+// 每个格式说明中列出的选项都应是选项类型中的字段，默认值可以直接传回
+func TestFormatOptionsMatchFields(t *testing.T) {
+	for _, info := range Formats() {
+		e, ok := LookupExporter(info.ID)
+		if !ok {
+			t.Fatalf("%s 未注册", info.ID)
+		}
+		for _, opt := range info.Options {
+			data, _ := json.Marshal(map[string]any{opt.Name: opt.Default})
+			if err := e.Validate(data); err != nil && strings.Contains(err.Error(), "unknown field") {
+				t.Errorf("%s.%s: %v", info.ID, opt.Name, err)
+			}
+		}
+		for _, id := range info.Requires {
+			if _, ok := LookupExporter(id); !ok {
+				t.Errorf("%s 依赖未注册的格式 %s", info.ID, id)
+			}
+		}
+	}
+}
+
+func TestRegisterExporterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应panic")
+		}
+	}()
+	RegisterExporter(NewExporter(FormatInfo{ID: CSSFormat}, none, func(ExportContext, noOptions) ([]ExportFile, error) {
+		return nil, nil
+	}))
+}
+
+// 没有必填选项的格式使用默认选项导出，导出的文件都在输出目录中
+// unrotatedFrames 返回atlasFrames中的帧，旋转存放的帧改为未旋转
+func unrotatedFrames() []Frame {
+	frames := atlasFrames()
+	for i := range frames {
+		if frames[i].Rotated() {
+			w, h := frames[i].Width(), frames[i].Height()
+			frames[i].Rect = NewRect(frames[i].Rect.LT.X, frames[i].Rect.LT.Y, w, h)
+			frames[i].Rotation = 0
+		}
+	}
+	return frames
+}
+
+func TestExportAllFormats(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	ctx := ExportContext{
+		Sheet:    sheet,
+		BaseName: "hero_a1b2c3d4_1700000000",
+		Name:     "hero",
+		CSS:      defaultCSSOptions(),
+		Result:   &SaveResult{},
+		ReadFile: func(name string) ([]byte, string, error) {
+			return []byte("{{range .Sprites}}{{.Name}}\n{{end}}"), name, nil
+		},
+	}
+	options := map[string]string{
+		BMFont:         `{"chars": "abcd"}`,
+		TemplateFormat: `{"file": "names.txt.tmpl", "output": "names.txt"}`,
+	}
+	for _, info := range Formats() {
+		e, _ := LookupExporter(info.ID)
+		files, err := e.Export(ctx, json.RawMessage(options[info.ID]))
+		if errors.Is(err, ErrUnsupportedFrame) {
+			// 无法表示顺时针旋转的格式改用未旋转的帧
+			flat := ctx
+			flat.Sheet = testSheet(32, 16, unrotatedFrames()...)
+			files, err = e.Export(flat, json.RawMessage(options[info.ID]))
+		}
+		if err != nil {
+			t.Errorf("%s: %v", info.ID, err)
+			continue
+		}
+		if len(files) == 0 {
+			t.Errorf("%s 没有导出文件", info.ID)
+		}
+		for _, file := range files {
+			if file.Name == "" || strings.HasPrefix(file.Name, "/") || strings.Contains(file.Name, "..") {
+				t.Errorf("%s 导出的文件名 %q 不在输出目录中", info.ID, file.Name)
+			}
+		}
+	}
+}
+
+func TestExportFileNamesDoNotCollide(t *testing.T) {
+	sheet := testSheet(24, 8,
+		Frame{Name: "hero.png", Rect: NewRect(0, 0, 8, 8)},
+		Frame{Name: "walk.png", Rect: NewRect(8, 0, 8, 8)},
+		Frame{Name: "anim/walk.png", Rect: NewRect(16, 0, 8, 8)},
+	)
+	ctx := ExportContext{Sheet: sheet, BaseName: "hero", Name: "hero", CSS: defaultCSSOptions()}
+	seen := map[string]string{}
+	for _, id := range []string{SheetFormat, SpritesFormat} {
+		e, _ := LookupExporter(id)
+		files, err := e.Export(ctx, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		for _, file := range files {
+			if prev, ok := seen[file.Name]; ok {
+				t.Errorf("%s 导出的 %s 覆盖了 %s 的文件", id, file.Name, prev)
+			}
+			seen[file.Name] = id
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("导出文件 = %v, want 4个", seen)
+	}
+}
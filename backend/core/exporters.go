@@ -0,0 +1,497 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// 内置的基础导出格式
+const (
+	CSSFormat     = "css"
+	JSONFormat    = "json"
+	SheetFormat   = "sheet"
+	SpritesFormat = "sprites"
+	PreviewFormat = "preview"
+)
+
+// noOptions 没有选项的导出格式使用的选项类型
+type noOptions struct{}
+
+// none 返回空选项
+func none() noOptions {
+	return noOptions{}
+}
+
+// cssNaming 样式相关格式的说明中引用的CSS命名规则
+const cssNaming = "类名沿用css格式的命名规则"
+
+// physicsOptions 碰撞形状导出格式共用的选项
+var physicsOptions = []FormatOption{
+	{Name: "parts", Type: "integer", Description: "每个精灵最多的凸多边形数"},
+	{Name: "vertices", Type: "integer", Description: "每个多边形最多的顶点数"},
+	{Name: "tolerance", Type: "number", Description: "简化轮廓时允许的最大偏差（像素）"},
+	{Name: "flip_y", Type: "boolean", Description: "y轴向上，适用于Box2D等引擎"},
+}
+
+func init() {
+	// 基础导出：CSS、JSON、图集、精灵图和预览页面
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          CSSFormat,
+		Name:        "CSS",
+		Description: "每个精灵一个类，通过background-position引用图集",
+		Extensions:  []string{".css"},
+		Default:     true,
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "prefix", Type: "string", Description: "所有精灵共用的类名"},
+			{Name: "template", Type: "string", Description: "精灵类名模板，可用{prefix}、{name}、{index}"},
+			{Name: "image", Type: "string", Description: "图集的url，默认使用图集文件名"},
+			{Name: "retina", Type: "boolean", Description: "同时输出2倍图集，使用image-set和媒体查询引用"},
+			{Name: "retina_file", Type: "string", Description: "已上传的2倍图集文件名，不指定时将图集按最近邻放大2倍，只是像素放大，不会增加细节"},
+			{Name: "variables", Type: "boolean", Description: "为每个精灵输出CSS自定义属性"},
+			{Name: "minify", Type: "boolean", Description: "压缩输出"},
+		},
+	}, defaultCSSOptions, func(ctx ExportContext, opts CSSOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".css", GetCSS(ctx.Sheet, opts)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          JSONFormat,
+		Name:        "JSON",
+		Description: "sprite-cuter的帧信息JSON，结构见 /api/v1/schema/sheet.json",
+		Extensions:  []string{".json"},
+		Default:     true,
+		Options: []FormatOption{
+			{Name: "legacy", Type: "boolean", Description: "输出旧版结构"},
+		},
+	}, func() JSONOptions { return JSONOptions{} }, func(ctx ExportContext, opts JSONOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".json", GetJson(ctx.Sheet, opts.Legacy)), nil
+	}))
+
+	// 表格导出
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          CSVFormat,
+		Name:        "CSV",
+		Description: "带表头的精灵列表，包含名称、序号、区域、锚点、所属动画和不透明像素数",
+		Extensions:  []string{".csv"},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".csv", GetCSV(ctx.Sheet)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          YAMLFormat,
+		Name:        "YAML",
+		Description: "与CSV字段相同的YAML精灵列表",
+		Extensions:  []string{".yaml"},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".yaml", GetYAML(ctx.Sheet)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          SheetFormat,
+		Name:        "图集",
+		Description: "复制到输出目录的图集PNG，CSS启用retina时同时输出2倍图集（未指定retina_file时为最近邻放大）",
+		Extensions:  []string{".png"},
+		Default:     true,
+		Options: []FormatOption{
+			{Name: "embed_atlas", Type: "boolean", Description: "将帧信息写入图集，再次上传时按其中的区域切割"},
+		},
+	}, func() SheetOptions { return SheetOptions{} }, exportSheet))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          SpritesFormat,
+		Name:        "精灵图",
+		Description: "每个精灵单独保存为PNG，旋转和去除透明边的帧还原为原始图像",
+		Extensions:  []string{".png"},
+		Default:     true,
+	}, none, exportSprites))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          PreviewFormat,
+		Name:        "预览页面",
+		Description: "离线的index.html，预览所有精灵、播放动画并复制引用代码",
+		Extensions:  []string{".html"},
+		Default:     true,
+		Requires:    []string{CSSFormat, SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		html, err := GetPreviewHTML(ctx.Sheet, ctx.BaseName+".css", ctx.CSS)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile("index.html", html), nil
+	}))
+
+	// 游戏引擎和图集工具的描述文件
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          TexturePackerHash,
+		Name:        "TexturePacker JSON (Hash)",
+		Description: "以帧名称为键的TexturePacker JSON，Phaser、PixiJS等引擎可直接加载",
+		Extensions:  []string{".hash.json"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		content, err := GetTexturePackerJson(ctx.Sheet, false)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile(ctx.BaseName+".hash.json", content), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          TexturePackerArray,
+		Name:        "TexturePacker JSON (Array)",
+		Description: "帧为数组的TexturePacker JSON",
+		Extensions:  []string{".array.json"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		content, err := GetTexturePackerJson(ctx.Sheet, true)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile(ctx.BaseName+".array.json", content), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          Cocos2dPlist,
+		Name:        "Cocos2d plist",
+		Description: "Cocos2d-x的format 3 plist",
+		Extensions:  []string{".plist"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		content, err := GetCocos2dPlist(ctx.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile(ctx.BaseName+".plist", content), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          StarlingXML,
+		Name:        "Starling / Sparrow XML",
+		Description: "Starling和Sparrow使用的TextureAtlas XML",
+		Extensions:  []string{".xml"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		content, err := GetStarlingXML(ctx.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile(ctx.BaseName+".xml", content), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          LibGDXAtlas,
+		Name:        "libGDX atlas",
+		Description: "libGDX TexturePacker的.atlas文本",
+		Extensions:  []string{".atlas"},
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "format", Type: "string", Description: "纹理像素格式"},
+			{Name: "filter", Type: "string", Description: "纹理过滤方式"},
+			{Name: "repeat", Type: "string", Description: "纹理重复方式"},
+			{Name: "legacy", Type: "boolean", Description: "输出libGDX 1.10之前的旧版写法"},
+		},
+	}, LibGDXOptions{}.withDefaults, func(ctx ExportContext, opts LibGDXOptions) ([]ExportFile, error) {
+		content, err := GetLibGDXAtlas(ctx.Sheet, opts)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile(ctx.BaseName+".atlas", content), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          UnityMeta,
+		Name:        "Unity .meta",
+		Description: "把图集导入为多精灵纹理的TextureImporter设置",
+		Extensions:  []string{".png.meta"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".png.meta", GetUnityMeta(ctx.Sheet, ctx.sourceName())), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          GodotTres,
+		Name:        "Godot 4 资源",
+		Description: "godot目录下每帧一个AtlasTexture，每个动画一个SpriteFrames",
+		Extensions:  []string{".tres"},
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "fps", Type: "number", Description: "动画帧率，帧时长按此换算为倍数"},
+			{Name: "loop", Type: "boolean", Description: "动画是否循环"},
+		},
+	}, GodotOptions{}.withDefaults, func(ctx ExportContext, opts GodotOptions) ([]ExportFile, error) {
+		return GetGodotResources(ctx.Sheet, ctx.BaseName, opts)
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          AsepriteJSON,
+		Name:        "Aseprite JSON",
+		Description: "Aseprite导出的JSON结构，动画写入frameTags",
+		Extensions:  []string{".aseprite.json"},
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "duration", Type: "integer", Description: "未指定时长的帧使用的时长（毫秒）"},
+			{Name: "array", Type: "boolean", Description: "输出Array格式，否则输出Hash格式"},
+		},
+	}, AsepriteOptions{}.withDefaults, func(ctx ExportContext, opts AsepriteOptions) ([]ExportFile, error) {
+		content, err := GetAsepriteJson(ctx.Sheet, opts)
+		if err != nil {
+			return nil, err
+		}
+		return singleFile(ctx.BaseName+".aseprite.json", content), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          TiledTSX,
+		Name:        "Tiled TSX",
+		Description: "Tiled图块集，网格图集引用整张图集，其他图集引用单帧图片",
+		Extensions:  []string{".tsx"},
+		Requires:    []string{SheetFormat, SpritesFormat},
+		Options: []FormatOption{
+			{Name: "collision", Type: "boolean", Description: "按轮廓生成碰撞多边形"},
+			{Name: "tolerance", Type: "number", Description: "简化轮廓时允许的最大偏差（像素）"},
+		},
+	}, TiledOptions{}.withDefaults, func(ctx ExportContext, opts TiledOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".tsx", GetTiledTSX(ctx.Sheet, ctx.BaseName, opts)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          BMFont,
+		Name:        "BMFont",
+		Description: "按阅读顺序把字形对应到字符，输出文本和XML两种.fnt",
+		Extensions:  []string{".fnt", ".fnt.xml"},
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "chars", Type: "string", Description: "按阅读顺序与字形对应的字符序列，必填"},
+			{Name: "face", Type: "string", Description: "字体名称，默认使用图集名称"},
+			{Name: "baseline", Type: "integer", Description: "基线到每行字形顶部的距离，默认自动检测"},
+			{Name: "spacing", Type: "integer", Description: "字符间距"},
+			{Name: "space", Type: "integer", Description: "空格的宽度，为0时取字形的平均宽度"},
+		},
+	}, BMFontOptions{}.withDefaults, func(ctx ExportContext, opts BMFontOptions) ([]ExportFile, error) {
+		text, xml, err := GetBMFont(ctx.Sheet, ctx.sourceName(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return []ExportFile{
+			{Name: ctx.BaseName + ".fnt", Content: text},
+			{Name: ctx.BaseName + ".fnt.xml", Content: xml},
+		}, nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          SVGFormat,
+		Name:        "SVG轮廓",
+		Description: "检测出的精灵轮廓，每个精灵一个path，孔洞为子路径，可引用图集作为背景",
+		Extensions:  []string{".svg"},
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "tolerance", Type: "number", Description: "简化轮廓时允许的最大偏差（像素），为0时保留像素边缘"},
+			{Name: "labels", Type: "boolean", Description: "显示精灵名称"},
+			{Name: "background", Type: "boolean", Description: "引用图集作为背景图"},
+			{Name: "separate", Type: "boolean", Description: "每个精灵输出一个SVG到svg目录"},
+			{Name: "stroke", Type: "string", Description: "轮廓颜色"},
+		},
+	}, func() SVGOptions { return SVGOptions{Labels: true}.withDefaults() }, func(ctx ExportContext, opts SVGOptions) ([]ExportFile, error) {
+		return GetSVG(ctx.Sheet, ctx.BaseName, opts), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          PhysicsEditor,
+		Name:        "PhysicsEditor JSON",
+		Description: "PhysicsEditor的Phaser（Matter.js）格式碰撞形状，由轮廓分解出的凸多边形，坐标相对锚点",
+		Extensions:  []string{".physics.json"},
+		Options:     physicsOptions,
+	}, PhysicsOptions{}.withDefaults, func(ctx ExportContext, opts PhysicsOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".physics.json", GetPhysicsEditorJSON(ctx.Sheet, opts)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          PhysicsJSON,
+		Name:        "碰撞形状JSON",
+		Description: "通用的碰撞形状JSON，每个精灵一组凸多边形，坐标相对锚点",
+		Extensions:  []string{".shapes.json"},
+		Options:     physicsOptions,
+	}, PhysicsOptions{}.withDefaults, func(ctx ExportContext, opts PhysicsOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".shapes.json", GetPhysicsJSON(ctx.Sheet, opts)), nil
+	}))
+
+	// 样式和前端代码
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          SCSS,
+		Name:        "SCSS",
+		Description: "精灵区域的map和按名称引用的mixin，" + cssNaming,
+		Extensions:  []string{".scss"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".scss", GetSCSS(ctx.Sheet, ctx.CSS)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          Less,
+		Name:        "Less",
+		Description: "精灵区域的map和按名称引用的mixin，" + cssNaming,
+		Extensions:  []string{".less"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".less", GetLess(ctx.Sheet, ctx.CSS)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          Tailwind,
+		Name:        "Tailwind插件",
+		Description: "为每个精灵添加utility类的Tailwind插件，" + cssNaming,
+		Extensions:  []string{".tailwind.js"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".tailwind.js", GetTailwindPlugin(ctx.Sheet, ctx.CSS)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          CSSKeyframes,
+		Name:        "CSS动画",
+		Description: "每个动画一个@keyframes和同名的类，名称为{prefix}-anim-{name}",
+		Extensions:  []string{".animations.css"},
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "duration", Type: "integer", Description: "未指定时长的帧使用的时长（毫秒）"},
+			{Name: "iterations", Type: "string", Description: "播放次数，infinite或正数"},
+			{Name: "direction", Type: "string", Enum: []string{"normal", "reverse", "alternate", "alternate-reverse"}, Description: "播放方向"},
+			{Name: "all", Type: "boolean", Description: "将所有帧按顺序作为一个动画，否则按帧名称分组"},
+		},
+	}, KeyframesOptions{}.withDefaults, func(ctx ExportContext, opts KeyframesOptions) ([]ExportFile, error) {
+		return singleFile(ctx.BaseName+".animations.css", GetCSSKeyframes(ctx.Sheet, opts, ctx.CSS)), nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          TypeScript,
+		Name:        "TypeScript",
+		Description: "ES模块和.d.ts类型声明，精灵和动画名称为字面量联合类型",
+		Extensions:  []string{".js", ".d.ts"},
+		Requires:    []string{SheetFormat},
+	}, none, func(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+		module, declaration, err := GetTypeScript(ctx.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		return []ExportFile{
+			{Name: ctx.BaseName + ".js", Content: module},
+			{Name: ctx.BaseName + ".d.ts", Content: declaration},
+		}, nil
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          GoPackage,
+		Name:        "Go包",
+		Description: "go目录下嵌入图集的Go包，包含精灵名称常量、区域和动画",
+		Extensions:  []string{".go"},
+		Options: []FormatOption{
+			{Name: "package", Type: "string", Description: "包名，默认由图集名称生成"},
+		},
+	}, func() GoOptions { return GoOptions{} }, func(ctx ExportContext, opts GoOptions) ([]ExportFile, error) {
+		return GetGoPackage(ctx.Sheet, ctx.sourceName(), opts)
+	}))
+
+	RegisterExporter(NewExporter(FormatInfo{
+		ID:          TemplateFormat,
+		Name:        "自定义模板",
+		Description: "使用Go text/template模板导出一个文件，" + cssNaming,
+		Requires:    []string{SheetFormat},
+		Options: []FormatOption{
+			{Name: "file", Type: "string", Description: "模板文件，API中为已上传的文件名，命令行中为文件路径，必填"},
+			{Name: "output", Type: "string", Description: "输出文件名，默认为 图集名称+模板文件去除.tmpl后的扩展名"},
+		},
+	}, func() TemplateOptions { return TemplateOptions{} }, exportTemplate))
+}
+
+// exportSheet 复制图集，需要时嵌入帧信息并输出2倍图集
+func exportSheet(ctx ExportContext, opts SheetOptions) ([]ExportFile, error) {
+	atlasJson := ""
+	if opts.EmbedAtlas {
+		atlasJson = GetJson(ctx.Sheet, false)
+	}
+	var b bytes.Buffer
+	if err := EncodeSheet(&b, ctx.Sheet.img, atlasJson, ctx.Save); err != nil {
+		return nil, err
+	}
+	files := singleFile(ctx.BaseName+".png", b.String())
+
+	if ctx.CSS.Retina {
+		img, err := retinaSheet(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var retina bytes.Buffer
+		if err := EncodeSheet(&retina, img, "", ctx.Save); err != nil {
+			return nil, err
+		}
+		files = append(files, ExportFile{Name: RetinaImage(ctx.BaseName + ".png"), Content: retina.String()})
+	}
+	return files, nil
+}
+
+// retinaSheet 返回2倍图集：读取CSS选项指定的2倍图集，未指定时将图集按最近邻放大2倍
+func retinaSheet(ctx ExportContext) (image.Image, error) {
+	if ctx.CSS.RetinaFile == "" {
+		return ScaleNearest(ctx.Sheet.img, 2), nil
+	}
+	if ctx.ReadFile == nil {
+		return nil, errors.New("无法读取2倍图集")
+	}
+	data, _, err := ctx.ReadFile(ctx.CSS.RetinaFile)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 2倍图集不是有效的PNG: %v", ErrInvalidSource, err)
+	}
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != ctx.Sheet.Width*2 || h != ctx.Sheet.Height*2 {
+		return nil, fmt.Errorf("%w: 2倍图集的尺寸为%dx%d，应为%dx%d", ErrInvalidSource, w, h, ctx.Sheet.Width*2, ctx.Sheet.Height*2)
+	}
+	return img, nil
+}
+
+// exportSprites 切割出每个精灵，累计保存结果
+func exportSprites(ctx ExportContext, _ noOptions) ([]ExportFile, error) {
+	files := make([]ExportFile, 0, len(ctx.Sheet.Frames))
+	names := frameFilenames(ctx.BaseName, ctx.Sheet.Frames)
+	for i, frame := range ctx.Sheet.Frames {
+		var b bytes.Buffer
+		result, err := EncodeSprite(&b, ctx.Sheet.img, frame, i, ctx.Save)
+		if err != nil {
+			// 记录错误但继续处理其他精灵
+			if ctx.Logf != nil {
+				ctx.Logf("保存精灵 %d 时出错: %v", i, err)
+			}
+			continue
+		}
+		if ctx.Result != nil {
+			ctx.Result.Size += result.Size
+			ctx.Result.OriginalSize += result.OriginalSize
+		}
+		files = append(files, ExportFile{Name: names[i], Content: b.String()})
+	}
+	return files, nil
+}
+
+// exportTemplate 读取用户模板并导出
+func exportTemplate(ctx ExportContext, opts TemplateOptions) ([]ExportFile, error) {
+	if opts.Text == "" {
+		if ctx.ReadFile == nil {
+			return nil, errors.New("无法读取模板文件")
+		}
+		text, name, err := ctx.ReadFile(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		opts.Text, opts.File = string(text), name
+	}
+	file, err := GetTemplate(ctx.Sheet, ctx.BaseName, opts, ctx.CSS)
+	if err != nil {
+		return nil, err
+	}
+	return []ExportFile{file}, nil
+}
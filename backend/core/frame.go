@@ -0,0 +1,211 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"path"
+	"strings"
+)
+
+// Frame 表示图集中的一帧
+type Frame struct {
+	Name     string // 帧名称，为空时按序号命名
+	Rect     Rect   // 在图集中占用的区域
+	Rotation int    // 在图集中存放时顺时针旋转的角度，0、90或270
+	Trimmed  bool   // 是否去除了透明边
+	SourceW  int    // 原始宽度
+	SourceH  int    // 原始高度
+	OffsetX  int    // 去除透明边后的图像在原始尺寸中的位置
+	OffsetY  int
+	Pivot    *Pivot // 锚点，为nil时使用中心点
+	Duration int    // 帧时长（毫秒），为0时未指定
+
+	Properties map[string]string // 自定义属性，如标签，随导出格式写出
+}
+
+// Pivot 表示帧的锚点，取值为相对原始尺寸的比例
+type Pivot struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// PivotOrDefault 返回帧的锚点，未设置时返回中心点
+func (f Frame) PivotOrDefault() Pivot {
+	if f.Pivot != nil {
+		return *f.Pivot
+	}
+	return Pivot{X: 0.5, Y: 0.5}
+}
+
+// FramesFromRects 将检测得到的区域转换为帧
+func FramesFromRects(rects []Rect) []Frame {
+	frames := make([]Frame, 0, len(rects))
+	for _, rect := range rects {
+		frames = append(frames, Frame{
+			Rect:    rect,
+			SourceW: rect.RT.X - rect.LT.X,
+			SourceH: rect.RB.Y - rect.RT.Y,
+		})
+	}
+	return frames
+}
+
+// FrameRects 返回帧在图集中占用的区域
+func FrameRects(frames []Frame) []Rect {
+	rects := make([]Rect, 0, len(frames))
+	for _, frame := range frames {
+		rects = append(rects, frame.Rect)
+	}
+	return rects
+}
+
+// Rotated 帧在图集中是否旋转存放
+func (f Frame) Rotated() bool {
+	return f.Rotation == 90 || f.Rotation == 270
+}
+
+// Width 帧在未旋转时的宽度（去除透明边后）
+func (f Frame) Width() int {
+	if f.Rotated() {
+		return f.Rect.RB.Y - f.Rect.RT.Y
+	}
+	return f.Rect.RT.X - f.Rect.LT.X
+}
+
+// Height 帧在未旋转时的高度（去除透明边后）
+func (f Frame) Height() int {
+	if f.Rotated() {
+		return f.Rect.RT.X - f.Rect.LT.X
+	}
+	return f.Rect.RB.Y - f.Rect.RT.Y
+}
+
+// SourceSize 返回帧的原始尺寸
+func (f Frame) SourceSize() (int, int) {
+	w, h := f.SourceW, f.SourceH
+	if w <= 0 || h <= 0 {
+		return f.Width(), f.Height()
+	}
+	return w, h
+}
+
+// FrameName 返回帧名称，未命名的帧按序号命名为 spriteN
+func FrameName(index int, frame Frame) string {
+	if frame.Name != "" {
+		return frame.Name
+	}
+	return fmt.Sprintf("sprite%d", index)
+}
+
+// frameFilename 返回帧图片的文件名（不含目录）
+// 未命名的帧沿用 目录名+序号 的命名，导入的帧使用原始帧名
+func frameFilename(outDir string, index int, frame Frame) string {
+	if frame.Name == "" {
+		return fmt.Sprintf("%s%d.png", outDir, index)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, trimImageExt(frame.Name))
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		name = fmt.Sprintf("%s%d", outDir, index)
+	}
+	return name + ".png"
+}
+
+// frameFilenames 返回各帧图片的文件名，重名的帧加上序号，
+// 与图集或2倍图集同名的帧也加上序号，避免互相覆盖
+func frameFilenames(baseName string, frames []Frame) []string {
+	names := make([]string, 0, len(frames)+2)
+	names = append(names, baseName+".png", RetinaImage(baseName+".png"))
+	for i, frame := range frames {
+		names = append(names, frameFilename(baseName, i, frame))
+	}
+	return uniqueNames(names)[2:]
+}
+
+// ValidateFrames 检查从图集描述文件或嵌入信息读取的帧能否在图集中切割：
+// 区域、偏移和原始尺寸不能为负，区域须位于图集范围内，原始尺寸须能容纳去除透明边后的图像
+func ValidateFrames(frames []Frame, bounds image.Rectangle) error {
+	for i, frame := range frames {
+		name := FrameName(i, frame)
+		r := frame.Rect
+		if r.LT.X < 0 || r.LT.Y < 0 || r.RT.X < r.LT.X || r.RB.Y < r.RT.Y {
+			return fmt.Errorf("帧%s的区域无效: %+v", name, r)
+		}
+		if !image.Rect(r.LT.X, r.LT.Y, r.RB.X, r.RB.Y).In(bounds) {
+			return fmt.Errorf("帧%s的区域超出图集范围%dx%d", name, bounds.Dx(), bounds.Dy())
+		}
+		if frame.OffsetX < 0 || frame.OffsetY < 0 || frame.SourceW < 0 || frame.SourceH < 0 {
+			return fmt.Errorf("帧%s的偏移或原始尺寸为负", name)
+		}
+		if frame.SourceW > 0 && frame.SourceH > 0 &&
+			(frame.OffsetX+frame.Width() > frame.SourceW || frame.OffsetY+frame.Height() > frame.SourceH) {
+			return fmt.Errorf("帧%s的原始尺寸%dx%d小于去除透明边后的区域", name, frame.SourceW, frame.SourceH)
+		}
+	}
+	return nil
+}
+
+// ErrUnsupportedFrame 导出格式无法表示的帧
+var ErrUnsupportedFrame = errors.New("导出格式无法表示的帧")
+
+// checkClockwise 检查帧是否都未旋转或顺时针旋转存放
+// TexturePacker、Cocos2d、Starling等格式只能表示顺时针旋转，
+// 从libGDX图集导入的逆时针旋转帧按原样写出会上下颠倒
+func checkClockwise(frames []Frame) error {
+	for i, frame := range frames {
+		if frame.Rotation == 270 {
+			return fmt.Errorf("%w: %s在图集中逆时针旋转存放，该格式只能表示顺时针旋转", ErrUnsupportedFrame, FrameName(i, frame))
+		}
+	}
+	return nil
+}
+
+// frameNames 返回各帧的名称，重复的名称加上序号，用作要求名称唯一的键
+func frameNames(frames []Frame) []string {
+	names := make([]string, len(frames))
+	for i, frame := range frames {
+		names[i] = FrameName(i, frame)
+	}
+	return uniqueNames(names)
+}
+
+// uniqueNames 为重复的名称加上序号，如第二个walk.png改为walk_2.png，返回的名称互不相同
+func uniqueNames(names []string) []string {
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		used[name] = true
+	}
+	result := make([]string, len(names))
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result[i] = name
+			continue
+		}
+		base := trimImageExt(name)
+		ext := name[len(base):]
+		candidate := name
+		for n := 2; used[candidate]; n++ {
+			candidate = fmt.Sprintf("%s_%d%s", base, n, ext)
+		}
+		used[candidate] = true
+		result[i] = candidate
+	}
+	return result
+}
+
+// trimImageExt 去除名称中的图片扩展名
+func trimImageExt(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".webp", ".tga":
+		return strings.TrimSuffix(name, path.Ext(name))
+	}
+	return name
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// testSheet 创建图集，帧所在的区域填充为不透明像素
+func testSheet(width, height int, frames ...Frame) Sheet {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for _, frame := range frames {
+		r := frame.Rect
+		for y := r.LT.Y; y < r.RB.Y; y++ {
+			for x := r.LT.X; x < r.RT.X; x++ {
+				img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+			}
+		}
+	}
+	return NewSheet("sheet.png", img, frames)
+}
+
+func TestUniqueNames(t *testing.T) {
+	got := uniqueNames([]string{"walk.png", "walk.png", "walk_2.png", "idle", "idle"})
+	want := []string{"walk.png", "walk_3.png", "walk_2.png", "idle", "idle_2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueNames = %v, want %v", got, want)
+	}
+}
+
+func TestFrameSourceSize(t *testing.T) {
+	frame := Frame{Rect: NewRect(0, 0, 4, 6), Rotation: 90}
+	if frame.Width() != 6 || frame.Height() != 4 {
+		t.Errorf("旋转帧的尺寸 = %dx%d, want 6x4", frame.Width(), frame.Height())
+	}
+	if w, h := frame.SourceSize(); w != 6 || h != 4 {
+		t.Errorf("SourceSize = %dx%d, want 6x4", w, h)
+	}
+	frame.SourceW, frame.SourceH = 10, 8
+	if w, h := frame.SourceSize(); w != 10 || h != 8 {
+		t.Errorf("SourceSize = %dx%d, want 10x8", w, h)
+	}
+}
+
+func TestFrameFilename(t *testing.T) {
+	tests := []struct {
+		frame Frame
+		want  string
+	}{
+		{Frame{}, "sheet3.png"},
+		{Frame{Name: "hero/walk_1.png"}, "hero_walk_1.png"},
+		{Frame{Name: "..png"}, "sheet3.png"},
+	}
+	for _, tt := range tests {
+		if got := frameFilename("sheet", 3, tt.frame); got != tt.want {
+			t.Errorf("frameFilename(%q) = %q, want %q", tt.frame.Name, got, tt.want)
+		}
+	}
+}
+
+func TestFrameFilenamesUnique(t *testing.T) {
+	frames := []Frame{
+		{Name: "walk.png"},
+		{Name: "walk.jpg"},
+		{Name: "a/walk"},
+		{Name: "a_walk.png"},
+		{Name: "sheet"},
+		{Name: "sheet@2x.png"},
+		{},
+	}
+	got := frameFilenames("sheet", frames)
+	want := []string{"walk.png", "walk_2.png", "a_walk.png", "a_walk_2.png", "sheet_2.png", "sheet@2x_2.png", "sheet6.png"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("frameFilenames = %v, want %v", got, want)
+	}
+}
+
+func TestValidateFrames(t *testing.T) {
+	bounds := image.Rect(0, 0, 16, 16)
+	valid := []Frame{
+		{Rect: NewRect(0, 0, 16, 16)},
+		{Rect: NewRect(4, 4, 4, 8), Rotation: 90, Trimmed: true, SourceW: 10, SourceH: 6, OffsetX: 2, OffsetY: 2},
+	}
+	if err := ValidateFrames(valid, bounds); err != nil {
+		t.Errorf("合法的帧: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		frame Frame
+	}{
+		{"负坐标", Frame{Rect: NewRect(-1, 0, 4, 4)}},
+		{"负宽度", Frame{Rect: NewRect(4, 0, -2, 4)}},
+		{"超出图集", Frame{Rect: NewRect(12, 12, 8, 4)}},
+		{"负偏移", Frame{Rect: NewRect(0, 0, 4, 4), Trimmed: true, SourceW: 8, SourceH: 8, OffsetX: -1}},
+		{"负原始尺寸", Frame{Rect: NewRect(0, 0, 4, 4), SourceW: -4, SourceH: 4}},
+		{"原始尺寸过小", Frame{Rect: NewRect(0, 0, 4, 4), Trimmed: true, SourceW: 3, SourceH: 4}},
+		{"偏移超出原始尺寸", Frame{Rect: NewRect(0, 0, 4, 4), Trimmed: true, SourceW: 6, SourceH: 6, OffsetX: 3}},
+	}
+	for _, tt := range tests {
+		if err := ValidateFrames([]Frame{tt.frame}, bounds); err == nil {
+			t.Errorf("%s: 应返回错误", tt.name)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+)
+
+// Godot 4 文本资源导出格式
+const GodotTres = "godot-tres"
+
+// godotDir Godot资源在输出目录中的子目录
+const godotDir = "godot"
+
+// GodotOptions Godot 4 资源导出选项
+type GodotOptions struct {
+	FPS  float64 `json:"fps"`  // 动画帧率，默认10
+	Loop *bool   `json:"loop"` // 动画是否循环，默认循环
+}
+
+// withDefaults 补全默认选项
+func (o GodotOptions) withDefaults() GodotOptions {
+	if o.FPS <= 0 {
+		o.FPS = 10
+	}
+	if o.Loop == nil {
+		loop := true
+		o.Loop = &loop
+	}
+	return o
+}
+
+// GetGodotResources 生成Godot 4 的文本资源，返回相对输出目录的文件路径和内容
+// 每个帧生成一个AtlasTexture，每组动画生成一个SpriteFrames。
+// 资源以相对路径引用图集，导入Godot后由编辑器转换为res://路径。
+// 帧时长写为相对动画帧率的倍数，未指定时长的帧为1。
+// AtlasTexture不能表示旋转存放的帧，这类帧返回ErrUnsupportedFrame
+func GetGodotResources(sheet Sheet, baseName string, opts GodotOptions) ([]ExportFile, error) {
+	opts = opts.withDefaults()
+	for i, frame := range sheet.Frames {
+		if frame.Rotated() {
+			return nil, fmt.Errorf("%w: %s在图集中旋转存放，Godot的AtlasTexture不支持旋转", ErrUnsupportedFrame, FrameName(i, frame))
+		}
+	}
+	imagePath := "../" + sheet.Image
+	var files []ExportFile
+
+	// 帧和动画的资源文件名，同名时加上序号
+	animations := GroupAnimations(sheet.Frames)
+	names := frameFilenames(baseName, sheet.Frames)
+	for i, name := range names {
+		names[i] = strings.TrimSuffix(name, ".png") + ".tres"
+	}
+	for _, animation := range animations {
+		name := frameFilename(baseName, 0, Frame{Name: animation.Name})
+		names = append(names, strings.TrimSuffix(name, ".png")+"_frames.tres")
+	}
+	names = uniqueNames(names)
+
+	// 每个帧一个AtlasTexture
+	for i, frame := range sheet.Frames {
+		var b strings.Builder
+		b.WriteString("[gd_resource type=\"AtlasTexture\" load_steps=2 format=3]\n\n")
+		writeGodotTexture(&b, imagePath)
+		b.WriteString("[resource]\n")
+		writeGodotAtlasTexture(&b, frame)
+		files = append(files, ExportFile{Name: path.Join(godotDir, names[i]), Content: b.String()})
+	}
+
+	// 每组动画一个SpriteFrames，帧以内嵌的AtlasTexture引用图集
+	for a, animation := range animations {
+		var b strings.Builder
+		fmt.Fprintf(&b, "[gd_resource type=\"SpriteFrames\" load_steps=%d format=3]\n\n", len(animation.Frames)+2)
+		writeGodotTexture(&b, imagePath)
+		for n, index := range animation.Frames {
+			fmt.Fprintf(&b, "[sub_resource type=\"AtlasTexture\" id=\"AtlasTexture_%d\"]\n", n)
+			writeGodotAtlasTexture(&b, sheet.Frames[index])
+			b.WriteString("\n")
+		}
+		b.WriteString("[resource]\n")
+		b.WriteString("animations = [{\n")
+		b.WriteString("\"frames\": [")
+		for n, index := range animation.Frames {
+			if n > 0 {
+				b.WriteString(", ")
+			}
+			duration := godotDuration(sheet.Frames[index], opts.FPS)
+			fmt.Fprintf(&b, "{\n\"duration\": %s,\n\"texture\": SubResource(\"AtlasTexture_%d\")\n}", godotFloat(duration), n)
+		}
+		b.WriteString("],\n")
+		fmt.Fprintf(&b, "\"loop\": %t,\n", *opts.Loop)
+		fmt.Fprintf(&b, "\"name\": &%s,\n", godotString(animation.Name))
+		fmt.Fprintf(&b, "\"speed\": %s\n", godotFloat(opts.FPS))
+		b.WriteString("}]\n")
+
+		name := names[len(sheet.Frames)+a]
+		files = append(files, ExportFile{Name: path.Join(godotDir, name), Content: b.String()})
+	}
+	return files, nil
+}
+
+// godotDuration 返回帧时长相对动画帧率的倍数，保留三位小数
+func godotDuration(frame Frame, fps float64) float64 {
+	if frame.Duration <= 0 {
+		return 1
+	}
+	return math.Round(float64(frame.Duration)*fps) / 1000
+}
+
+// writeGodotTexture 写入引用图集的ext_resource
+func writeGodotTexture(b *strings.Builder, imagePath string) {
+	fmt.Fprintf(b, "[ext_resource type=\"Texture2D\" path=%s id=\"1_sheet\"]\n\n", godotString(imagePath))
+}
+
+// writeGodotAtlasTexture 写入AtlasTexture的属性，去除透明边的帧通过margin还原原始尺寸
+func writeGodotAtlasTexture(b *strings.Builder, frame Frame) {
+	x, y := frame.Rect.LT.X, frame.Rect.LT.Y
+	width, height := frame.Rect.RT.X-frame.Rect.LT.X, frame.Rect.RB.Y-frame.Rect.RT.Y
+	b.WriteString("atlas = ExtResource(\"1_sheet\")\n")
+	fmt.Fprintf(b, "region = Rect2(%d, %d, %d, %d)\n", x, y, width, height)
+	if frame.Trimmed {
+		sourceW, sourceH := frame.SourceSize()
+		fmt.Fprintf(b, "margin = Rect2(%d, %d, %d, %d)\n", frame.OffsetX, frame.OffsetY, sourceW-width, sourceH-height)
+	}
+}
+
+// godotString 返回Godot资源文件中带引号的字符串
+func godotString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// godotFloat 格式化浮点数，整数值保留一位小数
+func godotFloat(v float64) string {
+	s := formatFloat(v)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// godotFiles 按文件名索引导出的资源
+func godotFiles(files []ExportFile) map[string]string {
+	byName := map[string]string{}
+	for _, file := range files {
+		byName[file.Name] = file.Content
+	}
+	return byName
+}
+
+func TestGodotResources(t *testing.T) {
+	sheet := testSheet(32, 16, unrotatedFrames()...)
+	exported, err := GetGodotResources(sheet, "hero", GodotOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := godotFiles(exported)
+
+	idle, ok := files["godot/idle.tres"]
+	if !ok {
+		t.Fatalf("缺少 idle.tres: %v", files)
+	}
+	for _, want := range []string{
+		`[gd_resource type="AtlasTexture" load_steps=2 format=3]`,
+		`[ext_resource type="Texture2D" path="../sheet.png" id="1_sheet"]`,
+		"region = Rect2(0, 0, 8, 8)\n",
+	} {
+		if !strings.Contains(idle, want) {
+			t.Errorf("idle.tres 缺少 %q:\n%s", want, idle)
+		}
+	}
+	if strings.Contains(idle, "margin") {
+		t.Error("未去除透明边的帧不应有margin")
+	}
+	if walk := files["godot/walk_1.tres"]; !strings.Contains(walk, "margin = Rect2(3, 1, 4, 4)\n") {
+		t.Errorf("walk_1.tres 的margin错误:\n%s", walk)
+	}
+
+	anim, ok := files["godot/walk_frames.tres"]
+	if !ok {
+		t.Fatalf("缺少 walk_frames.tres: %v", files)
+	}
+	for _, want := range []string{
+		`[gd_resource type="SpriteFrames" load_steps=4 format=3]`,
+		`[sub_resource type="AtlasTexture" id="AtlasTexture_1"]`,
+		`"texture": SubResource("AtlasTexture_1")`,
+		"\"loop\": true,\n",
+		"\"name\": &\"walk\",\n",
+		"\"speed\": 10.0\n",
+	} {
+		if !strings.Contains(anim, want) {
+			t.Errorf("walk_frames.tres 缺少 %q:\n%s", want, anim)
+		}
+	}
+}
+
+func TestGodotResourcesOptions(t *testing.T) {
+	loop := false
+	sheet := testSheet(32, 16, unrotatedFrames()...)
+	exported, err := GetGodotResources(sheet, "hero", GodotOptions{FPS: 12.5, Loop: &loop})
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim := godotFiles(exported)["godot/walk_frames.tres"]
+	if !strings.Contains(anim, "\"loop\": false,\n") || !strings.Contains(anim, "\"speed\": 12.5\n") {
+		t.Errorf("选项未生效:\n%s", anim)
+	}
+}
+
+func TestGodotResourcesDurations(t *testing.T) {
+	frames := unrotatedFrames()
+	frames[1].Duration = 100
+	frames[2].Duration = 200
+	exported, err := GetGodotResources(testSheet(32, 16, frames...), "hero", GodotOptions{FPS: 12.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim := godotFiles(exported)["godot/walk_frames.tres"]
+	// 帧时长为相对12.5帧每秒的倍数
+	for _, want := range []string{"\"duration\": 1.25,\n", "\"duration\": 2.5,\n"} {
+		if !strings.Contains(anim, want) {
+			t.Errorf("缺少 %q:\n%s", want, anim)
+		}
+	}
+	if strings.Contains(anim, "\"duration\": 1.0,") {
+		t.Errorf("指定了时长的帧不应使用默认倍数:\n%s", anim)
+	}
+}
+
+func TestGodotResourcesRejectRotated(t *testing.T) {
+	_, err := GetGodotResources(testSheet(32, 16, atlasFrames()...), "hero", GodotOptions{})
+	if !errors.Is(err, ErrUnsupportedFrame) {
+		t.Errorf("err = %v, want ErrUnsupportedFrame", err)
+	}
+}
+
+func TestGodotString(t *testing.T) {
+	if got := godotString("a\"b\\c\n"); got != `"a\"b\\c\n"` {
+		t.Errorf("godotString = %s", got)
+	}
+}
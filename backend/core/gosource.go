@@ -0,0 +1,204 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"image/png"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Go 源码导出格式，生成可用于Ebitengine的包
+const GoPackage = "go"
+
+// GoOptions Go 源码导出选项
+type GoOptions struct {
+	Package string `json:"package"` // 包名，默认由图集名称生成
+}
+
+// Validate 校验Go源码导出选项
+func (o GoOptions) Validate() error {
+	if o.Package != "" && (!token.IsIdentifier(o.Package) || token.IsKeyword(o.Package) || o.Package == "_") {
+		return fmt.Errorf("无效的包名: %s", o.Package)
+	}
+	return nil
+}
+
+// goReserved 生成的包中固定的标识符，精灵和动画的常量名不能与之重复
+var goReserved = []string{"Name", "Animation", "Rects", "Animations", "SheetPNG", "SubImager", "Sub", "Decode"}
+
+// GetGoPackage 生成Go包，包含每个精灵的名称常量和区域、动画帧序列，
+// 以及通过//go:embed嵌入的图集和按名称获取子图的函数，只依赖标准库。
+// 未指定包名时由图集名称name生成。
+// 返回包目录下的源码文件和图集文件，旋转存放的帧返回其在图集中占用的区域
+func GetGoPackage(sheet Sheet, name string, opts GoOptions) ([]ExportFile, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if sheet.img == nil {
+		return nil, errors.New("缺少图集图像")
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = goPackageName(name)
+	}
+	imageName := path.Base(sheet.Image)
+
+	// 精灵和动画名称对应的标识符，共用一个集合避免与固定的标识符及彼此重复
+	used := map[string]bool{}
+	for _, ident := range goReserved {
+		used[ident] = true
+	}
+	idents := make([]string, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		idents[i] = uniqueIdent(goIdent(trimImageExt(FrameName(i, frame)), "Sprite"), used)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by sprite-cuter. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "// Package %s 包含图集 %s 中的精灵区域和动画\n", pkg, imageName)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\"bytes\"\n_ \"embed\"\n\"image\"\n\"image/png\"\n)\n\n")
+	b.WriteString("// SheetPNG 图集图片的PNG数据\n//\n")
+	fmt.Fprintf(&b, "//go:embed %s\n", goEmbedPattern(imageName))
+	b.WriteString("var SheetPNG []byte\n\n")
+
+	b.WriteString("// Name 精灵名称\ntype Name string\n\n")
+	b.WriteString("// 图集中的精灵\nconst (\n")
+	for i, frame := range sheet.Frames {
+		fmt.Fprintf(&b, "%s Name = %s\n", idents[i], strconv.Quote(trimImageExt(FrameName(i, frame))))
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// Rects 精灵在图集中的区域\nvar Rects = map[Name]image.Rectangle{\n")
+	for i, frame := range sheet.Frames {
+		r := frame.Rect
+		fmt.Fprintf(&b, "%s: image.Rect(%d, %d, %d, %d),\n", idents[i], r.LT.X, r.LT.Y, r.RB.X, r.RB.Y)
+	}
+	b.WriteString("}\n\n")
+
+	// 动画名称和帧序列
+	animations := GroupAnimations(sheet.Frames)
+	b.WriteString("// Animation 动画名称\ntype Animation string\n\n")
+	if len(animations) > 0 {
+		b.WriteString("// 按帧名称分组的动画\nconst (\n")
+		animIdents := make([]string, len(animations))
+		for n, animation := range animations {
+			animIdents[n] = uniqueIdent("Anim"+goIdent(animation.Name, ""), used)
+			fmt.Fprintf(&b, "%s Animation = %s\n", animIdents[n], strconv.Quote(animation.Name))
+		}
+		b.WriteString(")\n\n")
+		b.WriteString("// Animations 按帧号排列的动画帧\nvar Animations = map[Animation][]Name{\n")
+		for n, animation := range animations {
+			fmt.Fprintf(&b, "%s: {\n", animIdents[n])
+			for _, index := range animation.Frames {
+				fmt.Fprintf(&b, "%s,\n", idents[index])
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("}\n\n")
+	} else {
+		b.WriteString("// Animations 按帧号排列的动画帧\nvar Animations = map[Animation][]Name{}\n\n")
+	}
+
+	b.WriteString(`// SubImager 可以按区域截取子图的图像，*ebiten.Image和*image.RGBA等都满足此接口
+type SubImager interface {
+	SubImage(r image.Rectangle) image.Image
+}
+
+// Sub 返回精灵对应的子图，精灵不存在时返回nil
+func Sub(img SubImager, name Name) image.Image {
+	r, ok := Rects[name]
+	if !ok {
+		return nil
+	}
+	return img.SubImage(r)
+}
+
+// Decode 解码嵌入的图集图片，可通过ebiten.NewImageFromImage转换为*ebiten.Image
+func Decode() (image.Image, error) {
+	return png.Decode(bytes.NewReader(SheetPNG))
+}
+`)
+
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, err
+	}
+	var sheetPNG bytes.Buffer
+	if err := png.Encode(&sheetPNG, sheet.img); err != nil {
+		return nil, err
+	}
+	dir := path.Join("go", pkg)
+	return []ExportFile{
+		{Name: path.Join(dir, pkg+".go"), Content: string(source)},
+		{Name: path.Join(dir, imageName), Content: sheetPNG.String()},
+	}, nil
+}
+
+// goPackageName 由名称生成合法的包名
+func goPackageName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	pkg := b.String()
+	if pkg == "" || unicode.IsDigit(rune(pkg[0])) {
+		pkg = "sprites" + pkg
+	}
+	if token.IsKeyword(pkg) {
+		pkg += "sprites"
+	}
+	return pkg
+}
+
+// goIdent 将名称转换为导出的Go标识符，如 walk_01 转换为 Walk01
+// 不以字母开头时加上prefix
+func goIdent(name, prefix string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r >= 0x80 || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	ident := b.String()
+	if ident == "" || !unicode.IsLetter(rune(ident[0])) {
+		if prefix == "" {
+			prefix = "X"
+		}
+		ident = prefix + ident
+	}
+	return ident
+}
+
+// uniqueIdent 名称重复时加上序号
+func uniqueIdent(ident string, used map[string]bool) string {
+	candidate := ident
+	for n := 2; used[candidate]; n++ {
+		candidate = ident + "_" + strconv.Itoa(n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// goEmbedPattern 返回//go:embed中的文件名，包含空格等字符时加引号
+func goEmbedPattern(name string) string {
+	if strings.ContainsAny(name, " \t\"`") {
+		return strconv.Quote(name)
+	}
+	return name
+}
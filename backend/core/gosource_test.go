@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeExport 将导出的文件写入临时目录，返回目录
+func writeExport(t *testing.T, files []ExportFile) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGoPackageBuilds(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("没有go命令")
+	}
+
+	// 与生成的固定标识符、动画常量及彼此冲突的名称
+	names := []string{"rects", "Name", "sub", "decode", "sheet_png", "SubImager", "animations",
+		"walk_1", "walk_2", "anim_walk", "AnimWalk", "1up", "走路", "type", "rects_2"}
+	frames := make([]Frame, len(names))
+	for i, name := range names {
+		frames[i] = Frame{Name: name, Rect: NewRect(i*4, 0, 4, 4)}
+	}
+	sheet := testSheet(len(names)*4, 4, frames...)
+
+	files, err := GetGoPackage(sheet, "hero", GoOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := writeExport(t, append(files, ExportFile{Name: "go/go.mod", Content: "module example.com/sprites\n\ngo 1.21\n"}))
+
+	// 引用包中的标识符，确认生成的API可用
+	main := `package main
+
+import (
+	"image"
+
+	"example.com/sprites/hero"
+)
+
+func main() {
+	img, err := hero.Decode()
+	if err != nil {
+		panic(err)
+	}
+	for _, frames := range hero.Animations {
+		for _, name := range frames {
+			_ = hero.Sub(img.(hero.SubImager), name)
+		}
+	}
+	_ = image.Rectangle(hero.Rects[hero.Rects_2])
+}
+`
+	if err := os.MkdirAll(filepath.Join(dir, "go", "cmd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go", "cmd", "main.go"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "build", "./...")
+	cmd.Dir = filepath.Join(dir, "go")
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("生成的包无法编译: %v\n%s\n%s", err, out, files[0].Content)
+	}
+}
+
+func TestGoPackageDefaultName(t *testing.T) {
+	sheet := testSheet(4, 4, Frame{Name: "a", Rect: NewRect(0, 0, 4, 4)})
+	e, _ := LookupExporter(GoPackage)
+	files, err := e.Export(ExportContext{Sheet: sheet, BaseName: "my-hero_ab12CD34_1700000000", Name: "my-hero"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[0].Name != "go/myhero/myhero.go" {
+		t.Errorf("包文件 = %s, want go/myhero/myhero.go", files[0].Name)
+	}
+	if !strings.Contains(files[0].Content, "package myhero\n") {
+		t.Error("包名应去除上传时添加的随机后缀")
+	}
+}
+
+func TestGoOptionsValidate(t *testing.T) {
+	for _, name := range []string{"func", "_", "1abc", "a-b"} {
+		if err := (GoOptions{Package: name}).Validate(); err == nil {
+			t.Errorf("包名%q应返回错误", name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"image"
+)
+
+// GridOptions 按固定网格切割图集的参数
+type GridOptions struct {
+	Width     int  `json:"width"`      // 单元格宽度
+	Height    int  `json:"height"`     // 单元格高度
+	Margin    int  `json:"margin"`     // 图集边缘的留白
+	Spacing   int  `json:"spacing"`    // 单元格之间的间距
+	KeepEmpty bool `json:"keep_empty"` // 保留完全透明的单元格
+}
+
+// Validate 校验网格参数
+func (o GridOptions) Validate() error {
+	if o.Width <= 0 || o.Height <= 0 {
+		return errors.New("网格单元格的宽高必须大于0")
+	}
+	if o.Margin < 0 || o.Spacing < 0 {
+		return errors.New("网格的留白和间距不能为负数")
+	}
+	return nil
+}
+
+// GetGridSprites 按固定网格切割图集，返回的区域按从左到右、从上到下的顺序排列
+// 不足一个单元格的边缘部分会被忽略，默认跳过完全透明的单元格
+func GetGridSprites(img image.Image, opts GridOptions) []Rect {
+	var rects []Rect
+	if opts.Validate() != nil {
+		return rects
+	}
+	bounds := img.Bounds()
+	for y := opts.Margin; y+opts.Height <= bounds.Dy(); y += opts.Height + opts.Spacing {
+		for x := opts.Margin; x+opts.Width <= bounds.Dx(); x += opts.Width + opts.Spacing {
+			if !opts.KeepEmpty && isEmptyCell(img, x, y, opts.Width, opts.Height) {
+				continue
+			}
+			rects = append(rects, NewRect(x, y, opts.Width, opts.Height))
+		}
+	}
+	return rects
+}
+
+// isEmptyCell 判断单元格是否完全透明
+func isEmptyCell(img image.Image, x, y, width, height int) bool {
+	min := img.Bounds().Min
+	for py := y; py < y+height; py++ {
+		for px := x; px < x+width; px++ {
+			if alphaAt(img, min.X+px, min.Y+py) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
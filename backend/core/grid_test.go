@@ -0,0 +1,48 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestGetGridSprites(t *testing.T) {
+	// 1像素留白、2像素间距的2x2网格，右下角单元格为空，右侧和底部多出不足一格的边缘
+	img := image.NewNRGBA(image.Rect(0, 0, 12, 12))
+	for _, p := range []image.Point{{1, 1}, {6, 2}, {2, 6}} {
+		img.Set(p.X, p.Y, color.NRGBA{A: 255})
+	}
+	opts := GridOptions{Width: 3, Height: 3, Margin: 1, Spacing: 2}
+
+	got := GetGridSprites(img, opts)
+	want := []Rect{NewRect(1, 1, 3, 3), NewRect(6, 1, 3, 3), NewRect(1, 6, 3, 3)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetGridSprites = %v, want %v", got, want)
+	}
+
+	opts.KeepEmpty = true
+	if got := GetGridSprites(img, opts); len(got) != 4 || got[3] != NewRect(6, 6, 3, 3) {
+		t.Errorf("保留空单元格 = %v", got)
+	}
+}
+
+func TestGetGridSpritesOffsetBounds(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	img.Set(13, 11, color.NRGBA{A: 255})
+	got := GetGridSprites(img, GridOptions{Width: 2, Height: 2})
+	if want := []Rect{NewRect(2, 0, 2, 2)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetGridSprites = %v, want %v", got, want)
+	}
+}
+
+func TestGridOptionsValidate(t *testing.T) {
+	for _, bad := range []GridOptions{{Width: 0, Height: 1}, {Width: 1, Height: 1, Margin: -1}, {Width: 1, Height: 1, Spacing: -1}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v 应返回错误", bad)
+		}
+		if rects := GetGridSprites(image.NewNRGBA(image.Rect(0, 0, 4, 4)), bad); len(rects) != 0 {
+			t.Errorf("无效参数应不切割: %v", rects)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// alphaAt 返回像素的原始alpha值（16位精度）
+func alphaAt(img image.Image, x, y int) uint32 {
+	switch src := img.(type) {
+	case *image.NRGBA:
+		return uint32(src.Pix[src.PixOffset(x, y)+3]) * 0x101
+	case *image.RGBA:
+		return uint32(src.Pix[src.PixOffset(x, y)+3]) * 0x101
+	case *image.Paletted:
+		_, _, _, a := src.Palette[src.ColorIndexAt(x, y)].RGBA()
+		return a
+	}
+	_, _, _, a := img.At(x, y).RGBA()
+	return a
+}
+
+// alphaData 提取图像的alpha数据，按RGBA排列，只填充alpha通道
+// 16位图中低于8位精度的非零alpha仍视为不透明
+func alphaData(img image.Image) []uint8 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	data := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			a := alphaAt(img, bounds.Min.X+x, bounds.Min.Y+y)
+			idx := (y*width + x) * 4
+			data[idx+3] = uint8(a >> 8)
+			if a > 0 && data[idx+3] == 0 {
+				data[idx+3] = 1
+			}
+		}
+	}
+	return data
+}
+
+// pixImage 可以直接访问像素字节的图像
+type pixImage struct {
+	img    image.Image
+	pix    []uint8
+	stride int
+	bpp    int // 每像素字节数
+	min    image.Point
+}
+
+// offset 返回像素在pix中的偏移
+func (p pixImage) offset(x, y int) int {
+	return (y-p.min.Y)*p.stride + (x-p.min.X)*p.bpp
+}
+
+// asPixImage 获取图像的像素字节，不支持的颜色模型先转换为RGBA
+func asPixImage(img image.Image) pixImage {
+	switch src := img.(type) {
+	case *image.Paletted:
+		return pixImage{src, src.Pix, src.Stride, 1, src.Rect.Min}
+	case *image.Gray:
+		return pixImage{src, src.Pix, src.Stride, 1, src.Rect.Min}
+	case *image.Gray16:
+		return pixImage{src, src.Pix, src.Stride, 2, src.Rect.Min}
+	case *image.NRGBA:
+		return pixImage{src, src.Pix, src.Stride, 4, src.Rect.Min}
+	case *image.NRGBA64:
+		return pixImage{src, src.Pix, src.Stride, 8, src.Rect.Min}
+	case *image.RGBA:
+		return pixImage{src, src.Pix, src.Stride, 4, src.Rect.Min}
+	case *image.RGBA64:
+		return pixImage{src, src.Pix, src.Stride, 8, src.Rect.Min}
+	}
+
+	// 其他颜色模型统一转换为RGBA
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return pixImage{dst, dst.Pix, dst.Stride, 4, dst.Rect.Min}
+}
+
+// newLike 创建与源图颜色模型相同的透明图像
+// 调色板图沿用原调色板，未转换的颜色模型使用RGBA
+func newLike(img image.Image, width, height int) pixImage {
+	r := image.Rect(0, 0, width, height)
+	switch src := img.(type) {
+	case *image.Paletted:
+		dst := image.NewPaletted(r, src.Palette)
+		if idx := transparentIndex(src.Palette); idx > 0 {
+			for i := range dst.Pix {
+				dst.Pix[i] = idx
+			}
+		}
+		return asPixImage(dst)
+	case *image.Gray:
+		return asPixImage(image.NewGray(r))
+	case *image.Gray16:
+		return asPixImage(image.NewGray16(r))
+	case *image.NRGBA:
+		return asPixImage(image.NewNRGBA(r))
+	case *image.NRGBA64:
+		return asPixImage(image.NewNRGBA64(r))
+	case *image.RGBA64:
+		return asPixImage(image.NewRGBA64(r))
+	}
+	return asPixImage(image.NewRGBA(r))
+}
+
+// cropImage 按源图的颜色模型裁剪出指定区域
+// 返回图像大小为 width x height，超出源图范围的部分保持透明
+func cropImage(img image.Image, srcX, srcY, width, height int) image.Image {
+	src := asPixImage(img)
+	bounds := img.Bounds()
+	dst := newLike(src.img, width, height)
+
+	// 计算与源图相交的区域
+	srcRect := image.Rect(srcX, srcY, srcX+width, srcY+height).Add(bounds.Min).Intersect(bounds)
+	if srcRect.Empty() {
+		return dst.img
+	}
+	copyRows(dst.pix, dst.stride, src.pix, src.stride, src.offset(srcRect.Min.X, srcRect.Min.Y),
+		srcRect.Dx()*src.bpp, srcRect.Dy())
+	return dst.img
+}
+
+// rotateCCW 将图像逆时针旋转90度
+func rotateCCW(img image.Image) image.Image {
+	src := asPixImage(img)
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := newLike(src.img, height, width)
+
+	for y := 0; y < width; y++ {
+		for x := 0; x < height; x++ {
+			s := src.offset(bounds.Min.X+width-1-y, bounds.Min.Y+x)
+			d := y*dst.stride + x*dst.bpp
+			copy(dst.pix[d:d+dst.bpp], src.pix[s:s+src.bpp])
+		}
+	}
+	return dst.img
+}
+
+// rotateCW 将图像顺时针旋转90度
+func rotateCW(img image.Image) image.Image {
+	src := asPixImage(img)
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := newLike(src.img, height, width)
+
+	for y := 0; y < width; y++ {
+		for x := 0; x < height; x++ {
+			s := src.offset(bounds.Min.X+y, bounds.Min.Y+height-1-x)
+			d := y*dst.stride + x*dst.bpp
+			copy(dst.pix[d:d+dst.bpp], src.pix[s:s+src.bpp])
+		}
+	}
+	return dst.img
+}
+
+// padImage 将图像放入 width x height 的透明画布中的指定位置
+func padImage(img image.Image, width, height, offsetX, offsetY int) image.Image {
+	src := asPixImage(img)
+	bounds := img.Bounds()
+	dst := newLike(src.img, width, height)
+
+	// 计算与画布相交的区域
+	dstRect := image.Rect(offsetX, offsetY, offsetX+bounds.Dx(), offsetY+bounds.Dy()).Intersect(image.Rect(0, 0, width, height))
+	if dstRect.Empty() {
+		return dst.img
+	}
+	sx := bounds.Min.X + dstRect.Min.X - offsetX
+	sy := bounds.Min.Y + dstRect.Min.Y - offsetY
+	copyRows(dst.pix[dstRect.Min.Y*dst.stride+dstRect.Min.X*dst.bpp:], dst.stride, src.pix, src.stride,
+		src.offset(sx, sy), dstRect.Dx()*src.bpp, dstRect.Dy())
+	return dst.img
+}
+
+// copyRows 按行复制像素数据
+func copyRows(dst []uint8, dstStride int, src []uint8, srcStride, srcOffset, rowBytes, rows int) {
+	if rowBytes <= 0 || rows <= 0 {
+		return
+	}
+	for y := 0; y < rows; y++ {
+		s := srcOffset + y*srcStride
+		copy(dst[y*dstStride:y*dstStride+rowBytes], src[s:s+rowBytes])
+	}
+}
+
+// transparentIndex 返回调色板中第一个完全透明颜色的索引，不存在时返回0
+func transparentIndex(p color.Palette) uint8 {
+	for i, c := range p {
+		if _, _, _, a := c.RGBA(); a == 0 {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+// ScaleNearest 按整数倍放大图像，使用最近邻采样，像素画放大后保持清晰
+func ScaleNearest(img image.Image, factor int) image.Image {
+	src := asPixImage(img)
+	bounds := img.Bounds()
+	dst := newLike(src.img, bounds.Dx()*factor, bounds.Dy()*factor)
+	for y := 0; y < bounds.Dy()*factor; y++ {
+		for x := 0; x < bounds.Dx()*factor; x++ {
+			s := src.offset(bounds.Min.X+x/factor, bounds.Min.Y+y/factor)
+			d := y*dst.stride + x*dst.bpp
+			copy(dst.pix[d:d+dst.bpp], src.pix[s:s+src.bpp])
+		}
+	}
+	return dst.img
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// encodeDecode 编码帧对应的精灵图并解码
+func encodeDecode(t *testing.T, img image.Image, frame Frame) image.Image {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := EncodeSprite(&buf, img, frame, 0, SaveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestEncodeSpriteKeepsPalette(t *testing.T) {
+	palette := color.Palette{color.NRGBA{}, color.NRGBA{R: 255, A: 255}, color.NRGBA{G: 255, A: 255}}
+	img := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	img.SetColorIndex(1, 1, 1)
+	img.SetColorIndex(2, 1, 2)
+
+	out, ok := encodeDecode(t, img, Frame{Rect: NewRect(1, 1, 2, 1)}).(*image.Paletted)
+	if !ok {
+		t.Fatal("调色板图应输出调色板图")
+	}
+	if len(out.Palette) != len(palette) || out.ColorIndexAt(0, 0) != 1 || out.ColorIndexAt(1, 0) != 2 {
+		t.Errorf("调色板或索引未保留: %v %v", out.Palette, out.Pix)
+	}
+}
+
+func TestEncodeSpriteKeeps16Bit(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 4, 4))
+	want := color.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff}
+	img.SetNRGBA64(2, 2, want)
+
+	// 不透明的16位图解码为RGBA64
+	out := encodeDecode(t, img, Frame{Rect: NewRect(2, 2, 1, 1)})
+	if _, ok := out.(*image.RGBA64); !ok {
+		t.Fatalf("16位图应输出16位图, got %T", out)
+	}
+	if got := color.NRGBA64Model.Convert(out.At(0, 0)); got != want {
+		t.Errorf("像素 = %v, want %v", got, want)
+	}
+}
+
+func TestFrameImageRestoresRotationAndTrim(t *testing.T) {
+	// 2x3的帧顺时针旋转90度后存放为3x2
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(2, 0, color.NRGBA{R: 255, A: 255})
+	frame := Frame{Rect: NewRect(0, 0, 3, 2), Rotation: 90, Trimmed: true, SourceW: 4, SourceH: 5, OffsetX: 1, OffsetY: 1}
+
+	out := frameImage(img, frame)
+	if b := out.Bounds(); b.Dx() != 4 || b.Dy() != 5 {
+		t.Fatalf("尺寸 = %v, want 4x5", b)
+	}
+	// 存放时的右上角还原后位于左上角
+	if _, _, _, a := out.At(1, 1).RGBA(); a == 0 {
+		t.Error("旋转后的像素位置错误")
+	}
+	if _, _, _, a := out.At(0, 0).RGBA(); a != 0 {
+		t.Error("透明边应保持透明")
+	}
+}
+
+func TestAlphaDataKeepsFaint16BitPixels(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA64(1, 0, color.NRGBA64{A: 0x00ff})
+	data := alphaData(img)
+	if data[3] != 0 || data[7] == 0 {
+		t.Errorf("alpha = %d %d, want 0 和非零", data[3], data[7])
+	}
+}
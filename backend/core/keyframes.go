@@ -0,0 +1,139 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// CSS @keyframes 动画导出格式
+const CSSKeyframes = "css-keyframes"
+
+// KeyframesOptions CSS动画导出选项
+type KeyframesOptions struct {
+	Duration   int    `json:"duration"`   // 未指定时长的帧使用的时长（毫秒），默认100
+	Iterations string `json:"iterations"` // 播放次数，infinite或正数，默认infinite
+	Direction  string `json:"direction"`  // 播放方向，normal、reverse、alternate或alternate-reverse，默认normal
+	All        bool   `json:"all"`        // 将所有帧按顺序作为一个动画，否则按帧名称分组
+}
+
+// Validate 校验CSS动画选项
+func (o KeyframesOptions) Validate() error {
+	if o.Duration < 0 {
+		return errors.New("帧时长不能为负数")
+	}
+	if o.Iterations != "" && o.Iterations != "infinite" {
+		if n, err := strconv.ParseFloat(o.Iterations, 64); err != nil || n <= 0 || math.IsInf(n, 0) {
+			return fmt.Errorf("不支持的播放次数: %s", o.Iterations)
+		}
+	}
+	switch o.Direction {
+	case "", "normal", "reverse", "alternate", "alternate-reverse":
+	default:
+		return fmt.Errorf("不支持的播放方向: %s", o.Direction)
+	}
+	return nil
+}
+
+// withDefaults 补全默认选项
+func (o KeyframesOptions) withDefaults() KeyframesOptions {
+	if o.Duration <= 0 {
+		o.Duration = 100
+	}
+	if o.Iterations == "" {
+		o.Iterations = "infinite"
+	}
+	if o.Direction == "" {
+		o.Direction = "normal"
+	}
+	return o
+}
+
+// GetCSSKeyframes 生成按帧切换background-position的CSS动画
+// 每个动画生成一个@keyframes和一个同名的类，名称为{prefix}-anim-{name}，
+// all模式下为{prefix}-anim-all，不会与CSS导出的公共类名和精灵类名重复。
+// 动画中帧的尺寸统一为能容纳所有帧的大小，各帧按锚点对齐，
+// 图集中帧之间没有留白时，尺寸小于动画尺寸的帧会露出相邻的像素。
+// 关键帧的位置按帧时长分配，每段使用steps(1, end)保持到下一帧
+func GetCSSKeyframes(sheet Sheet, opts KeyframesOptions, cssOpts CSSOptions) string {
+	opts = opts.withDefaults()
+	w := &cssWriter{minify: cssOpts.Minify}
+	image := styleImage(sheet, cssOpts)
+
+	animations := GroupAnimations(sheet.Frames)
+	if opts.All {
+		all := Animation{Name: "all"}
+		for i := range sheet.Frames {
+			all.Frames = append(all.Frames, i)
+		}
+		animations = []Animation{all}
+	}
+
+	for _, animation := range animations {
+		if len(animation.Frames) == 0 {
+			continue
+		}
+		name := cssOpts.AnimationClass(animation.Name)
+
+		// 计算能容纳所有帧的尺寸和对齐后的锚点位置
+		var left, top, right, bottom float64
+		total := 0
+		for _, index := range animation.Frames {
+			frame := sheet.Frames[index]
+			pivot := frame.PivotOrDefault()
+			sourceW, sourceH := frame.SourceSize()
+			px, py := pivot.X*float64(sourceW), pivot.Y*float64(sourceH)
+			left, top = math.Max(left, px), math.Max(top, py)
+			right, bottom = math.Max(right, float64(sourceW)-px), math.Max(bottom, float64(sourceH)-py)
+			total += frameDuration(frame, opts.Duration)
+		}
+		width, height := int(math.Ceil(left+right)), int(math.Ceil(top+bottom))
+
+		// 关键帧
+		w.open("@keyframes " + name)
+		elapsed := 0
+		var position string
+		for _, index := range animation.Frames {
+			frame := sheet.Frames[index]
+			pivot := frame.PivotOrDefault()
+			sourceW, sourceH := frame.SourceSize()
+			x := int(math.Round(left-pivot.X*float64(sourceW))) + frame.OffsetX - frame.Rect.LT.X
+			y := int(math.Round(top-pivot.Y*float64(sourceH))) + frame.OffsetY - frame.Rect.LT.Y
+			position = fmt.Sprintf("background-position: %dpx %dpx", x, y)
+			w.rule(keyframePercent(elapsed, total), position)
+			elapsed += frameDuration(frame, opts.Duration)
+		}
+		// 最后一帧保持到动画结束
+		w.rule("100%", position)
+		w.close()
+
+		// 动画类
+		w.rule("."+name,
+			"display: inline-block",
+			"overflow: hidden",
+			"background-repeat: no-repeat",
+			"background-image: url("+cssString(image)+")",
+			fmt.Sprintf("width: %dpx", width),
+			fmt.Sprintf("height: %dpx", height),
+			fmt.Sprintf("animation: %s %dms steps(1, end) %s %s", name, total, opts.Iterations, opts.Direction),
+		)
+	}
+	return w.b.String()
+}
+
+// frameDuration 返回帧时长，未指定时使用默认时长
+func frameDuration(frame Frame, fallback int) int {
+	if frame.Duration > 0 {
+		return frame.Duration
+	}
+	return fallback
+}
+
+// keyframePercent 返回关键帧的百分比选择器
+func keyframePercent(elapsed, total int) string {
+	if total <= 0 {
+		return "0%"
+	}
+	return strconv.FormatFloat(math.Round(float64(elapsed)*10000/float64(total))/100, 'f', -1, 64) + "%"
+}
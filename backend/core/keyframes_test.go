@@ -0,0 +1,98 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// cssClasses 返回CSS中规则选择器里的类名
+func cssClasses(css string) map[string]bool {
+	classes := map[string]bool{}
+	for _, m := range regexp.MustCompile(`\.([-_A-Za-z0-9\x{80}-\x{10FFFF}]+)[^{};]*\{`).FindAllStringSubmatch(css, -1) {
+		classes[m[1]] = true
+	}
+	return classes
+}
+
+func TestCSSKeyframesClassesDoNotClash(t *testing.T) {
+	named := testSheet(32, 8,
+		Frame{Name: "walk_1", Rect: NewRect(0, 0, 8, 8)},
+		Frame{Name: "walk_2", Rect: NewRect(8, 0, 8, 8)},
+		Frame{Name: "sprite", Rect: NewRect(16, 0, 8, 8)},
+	)
+	detected := testSheet(32, 8, FramesFromRects([]Rect{NewRect(0, 0, 8, 8), NewRect(8, 0, 8, 8)})...)
+
+	for _, template := range []string{"{name}", "{index}", "{prefix}-{index}", "{prefix}-anim-{index}"} {
+		for _, all := range []bool{false, true} {
+			for _, sheet := range []Sheet{named, detected} {
+				cssOpts := CSSOptions{Prefix: "sprite", Template: template}
+				sprites := cssClasses(GetCSS(sheet, cssOpts))
+				animations := cssClasses(GetCSSKeyframes(sheet, KeyframesOptions{All: all}, cssOpts))
+				for class := range animations {
+					if sprites[class] {
+						t.Errorf("模板%s all=%v: 动画类.%s与CSS导出的类重复", template, all, class)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCSSKeyframes(t *testing.T) {
+	sheet := testSheet(32, 8,
+		Frame{Name: "walk_1", Rect: NewRect(0, 0, 8, 8), Duration: 100},
+		Frame{Name: "walk_2", Rect: NewRect(8, 0, 8, 8), Duration: 300},
+	)
+	css := GetCSSKeyframes(sheet, KeyframesOptions{Iterations: "2"}, defaultCSSOptions())
+	for _, want := range []string{
+		"@keyframes sprite-anim-walk {",
+		"0% {\n    background-position: 0px 0px;",
+		"25% {\n    background-position: -8px 0px;",
+		"100% {\n    background-position: -8px 0px;",
+		".sprite-anim-walk {",
+		"animation: sprite-anim-walk 400ms steps(1, end) 2 normal;",
+		"width: 8px;",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("缺少 %q:\n%s", want, css)
+		}
+	}
+}
+
+func TestCSSKeyframesDetectedSheet(t *testing.T) {
+	// 检测出的帧没有名称，不按默认名称合并为动画，只在all模式下生成动画
+	sheet := testSheet(16, 8, FramesFromRects([]Rect{NewRect(0, 0, 8, 8), NewRect(8, 0, 8, 8)})...)
+	if css := GetCSSKeyframes(sheet, KeyframesOptions{}, defaultCSSOptions()); css != "" {
+		t.Errorf("不应生成动画:\n%s", css)
+	}
+	css := GetCSSKeyframes(sheet, KeyframesOptions{All: true}, defaultCSSOptions())
+	if !strings.Contains(css, "@keyframes sprite-anim-all") || !strings.Contains(css, ".sprite-anim-all {") {
+		t.Errorf("all模式的动画名称错误:\n%s", css)
+	}
+}
+
+func TestTemplateGroupClass(t *testing.T) {
+	sheet := testSheet(16, 8,
+		Frame{Name: "walk_1", Rect: NewRect(0, 0, 8, 8)},
+		Frame{Name: "walk_2", Rect: NewRect(8, 0, 8, 8)},
+	)
+	cssOpts := CSSOptions{Prefix: "hero", Template: "{index}"}
+	model := NewTemplateModel(sheet, "sheet", cssOpts)
+	if len(model.Groups) != 1 || model.Groups[0].Class != "hero-anim-walk" {
+		t.Fatalf("Groups = %+v", model.Groups)
+	}
+	for _, sprite := range model.Sprites {
+		if sprite.Class == model.Groups[0].Class {
+			t.Errorf("动画类名与精灵%s的类名重复", sprite.Name)
+		}
+	}
+}
+
+func TestKeyframesOptionsValidate(t *testing.T) {
+	for _, opts := range []KeyframesOptions{{Duration: -1}, {Iterations: "0"}, {Iterations: "forever"}, {Direction: "up"}} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%+v应返回错误", opts)
+		}
+	}
+}
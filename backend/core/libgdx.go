@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LibGDX / Spine .atlas 导出格式
+const LibGDXAtlas = "libgdx-atlas"
+
+// LibGDXOptions LibGDX .atlas 导出选项
+type LibGDXOptions struct {
+	Format string `json:"format"` // 纹理像素格式，默认RGBA8888
+	Filter string `json:"filter"` // 纹理过滤方式，默认Nearest
+	Repeat string `json:"repeat"` // 纹理重复方式，默认none
+	Legacy bool   `json:"legacy"` // 输出libGDX 1.10之前的旧版写法
+}
+
+// withDefaults 补全默认选项
+func (o LibGDXOptions) withDefaults() LibGDXOptions {
+	if o.Format == "" {
+		o.Format = "RGBA8888"
+	}
+	if o.Filter == "" {
+		o.Filter = "Nearest"
+	}
+	if o.Repeat == "" {
+		o.Repeat = "none"
+	}
+	return o
+}
+
+// GetLibGDXAtlas 生成单页的libGDX TextureAtlas文本格式，见GetLibGDXAtlasPages
+func GetLibGDXAtlas(sheet Sheet, opts LibGDXOptions) (string, error) {
+	return GetLibGDXAtlasPages([]Sheet{sheet}, opts)
+}
+
+// GetLibGDXAtlasPages 生成libGDX TextureAtlas文本格式，Spine运行时同样可以读取
+// 每个图集对应一页纹理，各页写入自己的size、format、filter和repeat页头。
+// 帧名称末尾带帧号时，区域名称为动画名称并写入index，帧号在每页内单独推导。
+// libGDX的rotate表示逆时针旋转存放，libGDX和Spine都不能读取顺时针旋转存放的帧，这类帧返回ErrUnsupportedFrame
+func GetLibGDXAtlasPages(pages []Sheet, opts LibGDXOptions) (string, error) {
+	opts = opts.withDefaults()
+	for _, page := range pages {
+		for i, frame := range page.Frames {
+			if frame.Rotation == 90 {
+				return "", fmt.Errorf("%w: %s在图集中顺时针旋转存放，libGDX只能表示逆时针旋转", ErrUnsupportedFrame, FrameName(i, frame))
+			}
+		}
+	}
+	var b strings.Builder
+
+	// field 写入一个字段，旧版写法的区域字段需要缩进
+	field := func(indent bool, key, format string, args ...any) {
+		if opts.Legacy {
+			if indent {
+				b.WriteString("  ")
+			}
+			fmt.Fprintf(&b, "%s: %s\n", key, fmt.Sprintf(format, args...))
+			return
+		}
+		fmt.Fprintf(&b, "%s:%s\n", key, fmt.Sprintf(format, args...))
+	}
+	sep := ","
+	if opts.Legacy {
+		sep = ", "
+	}
+
+	for p, page := range pages {
+		if opts.Legacy || p > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(page.Image + "\n")
+		field(false, "size", "%d%s%d", page.Width, sep, page.Height)
+		field(false, "format", "%s", opts.Format)
+		field(false, "filter", "%s%s%s", opts.Filter, sep, opts.Filter)
+		field(false, "repeat", "%s", opts.Repeat)
+
+		// 按动画分组推导index
+		indexes := make([]int, len(page.Frames))
+		names := make([]string, len(page.Frames))
+		for i, frame := range page.Frames {
+			indexes[i] = -1
+			names[i] = trimImageExt(FrameName(i, frame))
+		}
+		for _, animation := range GroupAnimations(page.Frames) {
+			for _, i := range animation.Frames {
+				_, number, _ := FrameNumber(page.Frames[i])
+				names[i] = animation.Name
+				indexes[i] = number
+			}
+		}
+
+		for i, frame := range page.Frames {
+			b.WriteString(names[i] + "\n")
+			width, height := frame.Width(), frame.Height()
+			sourceW, sourceH := frame.SourceSize()
+			// offset从原图左下角计算
+			offsetY := sourceH - height - frame.OffsetY
+
+			rotated := frame.Rotation == 270
+
+			if opts.Legacy {
+				field(true, "rotate", "%t", rotated)
+				field(true, "xy", "%d, %d", frame.Rect.LT.X, frame.Rect.LT.Y)
+				field(true, "size", "%d, %d", width, height)
+				field(true, "orig", "%d, %d", sourceW, sourceH)
+				field(true, "offset", "%d, %d", frame.OffsetX, offsetY)
+				field(true, "index", "%d", indexes[i])
+				continue
+			}
+
+			field(true, "bounds", "%d,%d,%d,%d", frame.Rect.LT.X, frame.Rect.LT.Y, width, height)
+			if frame.Trimmed || sourceW != width || sourceH != height {
+				field(true, "offsets", "%d,%d,%d,%d", frame.OffsetX, offsetY, sourceW, sourceH)
+			}
+			if rotated {
+				field(true, "rotate", "90")
+			}
+			if indexes[i] >= 0 {
+				field(true, "index", "%d", indexes[i])
+			}
+		}
+	}
+	return b.String(), nil
+}
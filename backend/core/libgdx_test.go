@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// libgdxFrames libGDX往返测试的帧，区域名称不带扩展名，旋转的帧逆时针存放
+func libgdxFrames() []Frame {
+	frames := atlasFrames()
+	for i := range frames {
+		frames[i].Name = trimImageExt(frames[i].Name)
+		if frames[i].Rotation == 90 {
+			frames[i].Rotation = 270
+		}
+	}
+	return append(frames, Frame{Name: "roll", Rect: NewRect(0, 8, 3, 5), Rotation: 270, SourceW: 5, SourceH: 3})
+}
+
+func TestLibGDXAtlasRoundTrip(t *testing.T) {
+	want := libgdxFrames()
+	sheet := testSheet(32, 16, want...)
+	for _, legacy := range []bool{false, true} {
+		data, err := GetLibGDXAtlas(sheet, LibGDXOptions{Legacy: legacy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames, err := ParseAtlas("sheet.atlas", []byte(data))
+		if err != nil {
+			t.Fatalf("legacy=%v: %v\n%s", legacy, err, data)
+		}
+		assertFrames(t, frames, want)
+	}
+}
+
+func TestLibGDXAtlasIndexes(t *testing.T) {
+	sheet := testSheet(32, 16, libgdxFrames()...)
+	data, err := GetLibGDXAtlas(sheet, LibGDXOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"sheet.png\nsize:32,16\nformat:RGBA8888\nfilter:Nearest,Nearest\nrepeat:none\n",
+		"walk\nbounds:8,0,6,5\noffsets:3,3,10,9\nindex:1\n",
+		"walk\nbounds:16,0,7,5\noffsets:1,1,8,8\nrotate:90\nindex:2\n",
+		"roll\nbounds:0,8,5,3\nrotate:90\n",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("缺少 %q:\n%s", want, data)
+		}
+	}
+	// 检测出的未命名帧不合并为动画
+	detected, err := GetLibGDXAtlas(testSheet(16, 8, FramesFromRects([]Rect{NewRect(0, 0, 4, 4), NewRect(8, 0, 4, 4)})...), LibGDXOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(detected, "index:") || !strings.Contains(detected, "sprite0\n") || !strings.Contains(detected, "sprite1\n") {
+		t.Errorf("未命名帧:\n%s", detected)
+	}
+}
+
+func TestLibGDXAtlasRejectsClockwise(t *testing.T) {
+	_, err := GetLibGDXAtlas(testSheet(32, 16, atlasFrames()...), LibGDXOptions{})
+	if !errors.Is(err, ErrUnsupportedFrame) || !strings.Contains(err.Error(), "walk_2.png") {
+		t.Errorf("err = %v, want ErrUnsupportedFrame", err)
+	}
+}
+
+func TestLibGDXAtlasPagesRoundTrip(t *testing.T) {
+	first := testSheet(32, 16, libgdxFrames()...)
+	first.Image = "sheet1.png"
+	second := testSheet(16, 8,
+		Frame{Name: "coin_1", Rect: NewRect(0, 0, 4, 4), SourceW: 4, SourceH: 4},
+		Frame{Name: "coin_2", Rect: NewRect(4, 0, 4, 4), SourceW: 4, SourceH: 4},
+	)
+	second.Image = "sheet2.png"
+
+	for _, legacy := range []bool{false, true} {
+		data, err := GetLibGDXAtlasPages([]Sheet{first, second}, LibGDXOptions{Legacy: legacy, Filter: "Linear"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(data, "repeat") != 2 {
+			t.Errorf("legacy=%v: 每页应有自己的页头:\n%s", legacy, data)
+		}
+		pages, err := ParseLibGDXPages([]byte(data))
+		if err != nil {
+			t.Fatalf("legacy=%v: %v\n%s", legacy, err, data)
+		}
+		if len(pages) != 2 {
+			t.Fatalf("legacy=%v: 解析出 %d 页, want 2", legacy, len(pages))
+		}
+		for i, want := range []Sheet{first, second} {
+			got := pages[i]
+			if got.Image != want.Image || got.Width != want.Width || got.Height != want.Height {
+				t.Errorf("legacy=%v: 第 %d 页 = %s %dx%d, want %s %dx%d", legacy, i+1, got.Image, got.Width, got.Height, want.Image, want.Width, want.Height)
+			}
+			assertFrames(t, got.Frames, want.Frames)
+		}
+
+		// 切割单张图集时只读取第一页
+		frames, err := ParseAtlas("sheet.atlas", []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFrames(t, frames, first.Frames)
+	}
+}
@@ -0,0 +1,373 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"math"
+	"sort"
+)
+
+// 碰撞形状导出格式
+const (
+	PhysicsEditor = "physics-editor"
+	PhysicsJSON   = "physics-json"
+)
+
+// PhysicsOptions 碰撞形状导出选项
+type PhysicsOptions struct {
+	Parts     int     `json:"parts"`     // 每个精灵最多的凸多边形数，默认8
+	Vertices  int     `json:"vertices"`  // 每个多边形最多的顶点数，默认8（Box2D的上限）
+	Tolerance float64 `json:"tolerance"` // 简化轮廓时允许的最大偏差（像素），默认1
+	FlipY     bool    `json:"flip_y"`    // y轴向上，适用于Box2D等引擎
+}
+
+// Validate 校验碰撞形状导出选项
+func (o PhysicsOptions) Validate() error {
+	if o.Parts < 0 {
+		return errors.New("凸多边形数不能为负数")
+	}
+	if o.Vertices != 0 && o.Vertices < 3 {
+		return errors.New("多边形的顶点数至少为3")
+	}
+	if o.Tolerance < 0 {
+		return errors.New("轮廓简化偏差不能为负数")
+	}
+	return nil
+}
+
+// withDefaults 补全默认选项
+func (o PhysicsOptions) withDefaults() PhysicsOptions {
+	if o.Parts <= 0 {
+		o.Parts = 8
+	}
+	if o.Vertices <= 0 {
+		o.Vertices = 8
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = 1
+	}
+	return o
+}
+
+// PhysicsVertex 碰撞多边形的顶点，坐标相对精灵的锚点
+type PhysicsVertex struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// PhysicsBody 一个精灵的碰撞形状
+type PhysicsBody struct {
+	Name     string            `json:"name"`
+	Width    int               `json:"width"`  // 原始宽度
+	Height   int               `json:"height"` // 原始高度
+	Pivot    Pivot             `json:"pivot"`
+	Polygons [][]PhysicsVertex `json:"polygons"` // 凸多边形，顶点按逆时针排列（以y轴向上计）
+}
+
+// GetPhysicsBodies 由检测出的轮廓生成每个精灵的凸多边形碰撞形状
+// 凹多边形先三角化再按Hertel-Mehlhorn算法合并为凸多边形，合并时不超过顶点数上限；
+// 多边形数超过上限时逐步加大简化偏差重新分解，仍超过时保留面积最大的多边形
+func GetPhysicsBodies(sheet Sheet, opts PhysicsOptions) []PhysicsBody {
+	opts = opts.withDefaults()
+	bodies := make([]PhysicsBody, 0, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		sourceW, sourceH := frame.SourceSize()
+		pivot := frame.PivotOrDefault()
+		body := PhysicsBody{
+			Name:     trimImageExt(FrameName(i, frame)),
+			Width:    sourceW,
+			Height:   sourceH,
+			Pivot:    pivot,
+			Polygons: [][]PhysicsVertex{},
+		}
+		if sheet.img != nil {
+			px, py := pivot.X*float64(sourceW), pivot.Y*float64(sourceH)
+			for _, part := range framePolygons(sheet.img, frame, opts) {
+				polygon := make([]PhysicsVertex, len(part))
+				for j, p := range part {
+					x, y := roundVertex(float64(p.X)-px), roundVertex(float64(p.Y)-py)
+					if opts.FlipY {
+						y = -y
+					}
+					polygon[j] = PhysicsVertex{X: x, Y: y}
+				}
+				// 图像坐标y轴向下，逆时针排列的顶点翻转y轴后需要反转顺序
+				if opts.FlipY {
+					for l, r := 0, len(polygon)-1; l < r; l, r = l+1, r-1 {
+						polygon[l], polygon[r] = polygon[r], polygon[l]
+					}
+				}
+				body.Polygons = append(body.Polygons, polygon)
+			}
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies
+}
+
+// framePolygons 将帧的轮廓分解为不超过opts.Parts个凸多边形，坐标为帧原始尺寸中的像素角点坐标
+func framePolygons(img image.Image, frame Frame, opts PhysicsOptions) [][]Point {
+	var parts [][]Point
+	tolerance := opts.Tolerance
+	for attempt := 0; attempt < 6; attempt++ {
+		parts = parts[:0]
+		for _, contour := range FrameContours(img, frame, tolerance) {
+			parts = append(parts, ConvexDecompose(contour, opts.Vertices)...)
+		}
+		if len(parts) <= opts.Parts {
+			return parts
+		}
+		tolerance *= 2
+	}
+
+	sort.SliceStable(parts, func(a, b int) bool {
+		return polygonArea(parts[a]) > polygonArea(parts[b])
+	})
+	return parts[:opts.Parts]
+}
+
+// ConvexDecompose 将简单多边形分解为凸多边形，每个多边形不超过maxVertices个顶点
+// 返回的多边形按图像坐标（y轴向下）顺时针排列，即以y轴向上计的逆时针
+func ConvexDecompose(polygon []Point, maxVertices int) [][]Point {
+	if len(polygon) < 3 {
+		return nil
+	}
+	points := append([]Point{}, polygon...)
+	if signedArea(points) < 0 {
+		for l, r := 0, len(points)-1; l < r; l, r = l+1, r-1 {
+			points[l], points[r] = points[r], points[l]
+		}
+	}
+
+	// 三角化后合并相邻的多边形，合并结果仍为凸多边形且不超过顶点数上限时才合并
+	parts := triangulate(points)
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(parts) && !merged; i++ {
+			for j := i + 1; j < len(parts) && !merged; j++ {
+				if part, ok := mergeConvex(points, parts[i], parts[j], maxVertices); ok {
+					parts[i] = part
+					parts = append(parts[:j], parts[j+1:]...)
+					merged = true
+				}
+			}
+		}
+	}
+
+	result := make([][]Point, 0, len(parts))
+	for _, part := range parts {
+		convex := make([]Point, len(part))
+		for i, index := range part {
+			convex[i] = points[index]
+		}
+		result = append(result, convex)
+	}
+	return result
+}
+
+// triangulate 按耳切法三角化正向（signedArea为正）的多边形，返回顶点下标
+func triangulate(points []Point) [][]int {
+	remaining := make([]int, len(points))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	var triangles [][]int
+	for len(remaining) > 3 {
+		n := len(remaining)
+		ear, best := -1, 0
+		for i := range remaining {
+			a, b, c := remaining[(i+n-1)%n], remaining[i], remaining[(i+1)%n]
+			area := cross(points[a], points[b], points[c])
+			if area == 0 {
+				// 共线的顶点直接去除
+				ear, best = i, 0
+				break
+			}
+			if area < 0 {
+				continue
+			}
+			if !triangleContains(points, remaining, a, b, c) {
+				ear, best = i, area
+				break
+			}
+			// 自相交等退化的多边形找不到耳朵时，切掉最凸的顶点
+			if area > best {
+				best = area
+			}
+		}
+		if ear < 0 {
+			for i := range remaining {
+				a, b, c := remaining[(i+n-1)%n], remaining[i], remaining[(i+1)%n]
+				if cross(points[a], points[b], points[c]) == best {
+					ear = i
+					break
+				}
+			}
+		}
+		if ear < 0 {
+			break
+		}
+		if best > 0 {
+			triangles = append(triangles, []int{remaining[(ear+n-1)%n], remaining[ear], remaining[(ear+1)%n]})
+		}
+		remaining = append(remaining[:ear], remaining[ear+1:]...)
+	}
+	if len(remaining) == 3 && cross(points[remaining[0]], points[remaining[1]], points[remaining[2]]) > 0 {
+		triangles = append(triangles, remaining)
+	}
+	return triangles
+}
+
+// triangleContains 判断剩余顶点中是否有点落在三角形abc内或边上，与三角形顶点重合的点除外
+func triangleContains(points []Point, remaining []int, a, b, c int) bool {
+	pa, pb, pc := points[a], points[b], points[c]
+	for _, index := range remaining {
+		p := points[index]
+		if p == pa || p == pb || p == pc {
+			continue
+		}
+		if cross(pa, pb, p) >= 0 && cross(pb, pc, p) >= 0 && cross(pc, pa, p) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeConvex 沿公共边合并两个多边形，结果为凸多边形且顶点数不超过maxVertices时返回true
+func mergeConvex(points []Point, a, b []int, maxVertices int) ([]int, bool) {
+	for i := range a {
+		u, v := a[i], a[(i+1)%len(a)]
+		for j := range b {
+			if b[j] != v || b[(j+1)%len(b)] != u {
+				continue
+			}
+			// 从v沿a走到u，再沿b走回v
+			merged := make([]int, 0, len(a)+len(b)-2)
+			for k := 1; k <= len(a); k++ {
+				merged = append(merged, a[(i+k)%len(a)])
+			}
+			for k := 2; k < len(b); k++ {
+				merged = append(merged, b[(j+k)%len(b)])
+			}
+
+			// 检查凸性并去除共线的顶点
+			n := len(merged)
+			convex := make([]int, 0, n)
+			for k := range merged {
+				c := cross(points[merged[(k+n-1)%n]], points[merged[k]], points[merged[(k+1)%n]])
+				if c < 0 {
+					return nil, false
+				}
+				if c > 0 {
+					convex = append(convex, merged[k])
+				}
+			}
+			if len(convex) > maxVertices {
+				return nil, false
+			}
+			return convex, true
+		}
+	}
+	return nil, false
+}
+
+// signedArea 返回多边形有向面积的两倍，图像坐标中顺时针排列时为正
+func signedArea(points []Point) int {
+	area := 0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area
+}
+
+// polygonArea 返回多边形面积的两倍
+func polygonArea(points []Point) int {
+	area := signedArea(points)
+	if area < 0 {
+		return -area
+	}
+	return area
+}
+
+// GetPhysicsJSON 生成通用的碰撞形状JSON
+func GetPhysicsJSON(sheet Sheet, opts PhysicsOptions) string {
+	v := struct {
+		Image  string        `json:"image"`
+		FlipY  bool          `json:"flipY"`
+		Bodies []PhysicsBody `json:"bodies"`
+	}{sheet.Image, opts.FlipY, GetPhysicsBodies(sheet, opts)}
+
+	// 结构中只有字符串和数值，编码不会失败
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data)
+}
+
+// peBody PhysicsEditor的Phaser（Matter.js）格式中的一个精灵
+type peBody struct {
+	Type            string      `json:"type"`
+	Label           string      `json:"label"`
+	IsStatic        bool        `json:"isStatic"`
+	Density         float64     `json:"density"`
+	Restitution     float64     `json:"restitution"`
+	Friction        float64     `json:"friction"`
+	FrictionAir     float64     `json:"frictionAir"`
+	FrictionStatic  float64     `json:"frictionStatic"`
+	CollisionFilter peFilter    `json:"collisionFilter"`
+	Fixtures        []peFixture `json:"fixtures"`
+}
+
+// peFilter 碰撞过滤
+type peFilter struct {
+	Group    int `json:"group"`
+	Category int `json:"category"`
+	Mask     int `json:"mask"`
+}
+
+// peFixture 由多个凸多边形组成的夹具
+type peFixture struct {
+	Label    string            `json:"label"`
+	IsSensor bool              `json:"isSensor"`
+	Vertices [][]PhysicsVertex `json:"vertices"`
+}
+
+// GetPhysicsEditorJSON 生成PhysicsEditor的Phaser（Matter.js）格式JSON
+// 以精灵名称为键，可通过 this.matter.add.sprite(x, y, key, frame, {shape: shapes[name]}) 使用，
+// 物理参数为PhysicsEditor的默认值
+func GetPhysicsEditorJSON(sheet Sheet, opts PhysicsOptions) string {
+	keys := []string{"generator_info"}
+	values := []any{"Shape definitions generated by sprite-cuter"}
+	seen := map[string]bool{}
+	for _, body := range GetPhysicsBodies(sheet, opts) {
+		if seen[body.Name] {
+			continue
+		}
+		seen[body.Name] = true
+		keys = append(keys, body.Name)
+		values = append(values, peBody{
+			Type:            "fromPhysicsEditor",
+			Label:           body.Name,
+			Density:         0.1,
+			Restitution:     0,
+			Friction:        0.1,
+			FrictionAir:     0.01,
+			FrictionStatic:  0.5,
+			CollisionFilter: peFilter{Group: 0, Category: 1, Mask: 255},
+			Fixtures:        []peFixture{{Vertices: body.Polygons}},
+		})
+	}
+
+	// 结构中只有字符串和数值，编码不会失败
+	data, _ := marshalOrderedObject(keys, values)
+	var b bytes.Buffer
+	json.Indent(&b, data, "", "  ")
+	return b.String()
+}
+
+// roundVertex 保留两位小数，避免按锚点换算后出现过长的小数
+func roundVertex(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// assertConvexParts 检查分解结果：每个部分为正向的凸多边形，顶点数不超过上限，面积之和等于原多边形
+func assertConvexParts(t *testing.T, polygon []Point, parts [][]Point, maxVertices int) {
+	t.Helper()
+	total := 0
+	for _, part := range parts {
+		if len(part) < 3 || len(part) > maxVertices {
+			t.Errorf("多边形 %v 有 %d 个顶点, 上限 %d", part, len(part), maxVertices)
+		}
+		if signedArea(part) <= 0 {
+			t.Errorf("多边形 %v 的方向错误", part)
+		}
+		for i := range part {
+			a, b, c := part[i], part[(i+1)%len(part)], part[(i+2)%len(part)]
+			if (b.X-a.X)*(c.Y-b.Y)-(b.Y-a.Y)*(c.X-b.X) < 0 {
+				t.Errorf("多边形 %v 在 %v 处是凹的", part, b)
+			}
+		}
+		total += polygonArea(part)
+	}
+	if total != polygonArea(polygon) {
+		t.Errorf("分解后面积的两倍 = %d, want %d", total, polygonArea(polygon))
+	}
+}
+
+func TestConvexDecompose(t *testing.T) {
+	lShape := []Point{{0, 0}, {2, 0}, {2, 2}, {4, 2}, {4, 4}, {0, 4}}
+	comb := []Point{{0, 0}, {1, 0}, {1, 3}, {2, 3}, {2, 0}, {3, 0}, {3, 3}, {4, 3}, {4, 0}, {5, 0}, {5, 4}, {0, 4}}
+	reversed := []Point{{0, 4}, {4, 4}, {4, 2}, {2, 2}, {2, 0}, {0, 0}}
+	for _, polygon := range [][]Point{lShape, comb, reversed} {
+		for _, maxVertices := range []int{3, 4, 8} {
+			assertConvexParts(t, polygon, ConvexDecompose(polygon, maxVertices), maxVertices)
+		}
+	}
+	// L形最少分为两个凸多边形
+	if parts := ConvexDecompose(lShape, 8); len(parts) != 2 {
+		t.Errorf("L形分解为 %d 个多边形, want 2", len(parts))
+	}
+	// 凸多边形保持为一个
+	square := []Point{{0, 0}, {3, 0}, {3, 3}, {0, 3}}
+	if parts := ConvexDecompose(square, 8); len(parts) != 1 {
+		t.Errorf("正方形分解为 %d 个多边形", len(parts))
+	}
+	if parts := ConvexDecompose(square[:2], 8); parts != nil {
+		t.Errorf("少于3个点应返回nil: %v", parts)
+	}
+}
+
+func TestGetPhysicsBodies(t *testing.T) {
+	img := maskImage(
+		"##..",
+		"##..",
+		"####",
+		"####",
+	)
+	sheet := NewSheet("sheet.png", img, []Frame{{Name: "l.png", Rect: NewRect(0, 0, 4, 4)}})
+	bodies := GetPhysicsBodies(sheet, PhysicsOptions{Tolerance: 0.1})
+	if len(bodies) != 1 || bodies[0].Name != "l" || bodies[0].Width != 4 || len(bodies[0].Polygons) != 2 {
+		t.Fatalf("bodies = %+v", bodies)
+	}
+	// 坐标相对锚点（默认中心）
+	for _, polygon := range bodies[0].Polygons {
+		for _, v := range polygon {
+			if v.X < -2 || v.X > 2 || v.Y < -2 || v.Y > 2 {
+				t.Errorf("顶点 %+v 超出精灵范围", v)
+			}
+		}
+	}
+
+	// 翻转y轴后仍为以y轴向上计的逆时针
+	flipped := GetPhysicsBodies(sheet, PhysicsOptions{Tolerance: 0.1, FlipY: true})
+	for _, polygon := range flipped[0].Polygons {
+		area := 0.0
+		for i, p := range polygon {
+			q := polygon[(i+1)%len(polygon)]
+			area += p.X*q.Y - q.X*p.Y
+		}
+		if area <= 0 {
+			t.Errorf("翻转后的多边形 %+v 不是逆时针", polygon)
+		}
+	}
+
+	// 超过多边形数上限时只保留面积最大的
+	limited := GetPhysicsBodies(sheet, PhysicsOptions{Parts: 1, Vertices: 3, Tolerance: 0.1})
+	if len(limited[0].Polygons) != 1 {
+		t.Errorf("多边形数 = %d, want 1", len(limited[0].Polygons))
+	}
+}
+
+func TestGetPhysicsEditorJSON(t *testing.T) {
+	sheet := testSheet(32, 16, append(atlasFrames(), Frame{Name: "idle", Rect: NewRect(0, 8, 2, 2)})...)
+	data := GetPhysicsEditorJSON(sheet, PhysicsOptions{})
+	var shapes map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &shapes); err != nil {
+		t.Fatal(err)
+	}
+	// 重名的精灵只保留第一个
+	if len(shapes) != 5 || !strings.HasPrefix(data, "{\n  \"generator_info\"") {
+		t.Errorf("shapes = %v", shapes)
+	}
+	var idle peBody
+	if err := json.Unmarshal(shapes["idle"], &idle); err != nil {
+		t.Fatal(err)
+	}
+	if idle.Type != "fromPhysicsEditor" || len(idle.Fixtures) != 1 || len(idle.Fixtures[0].Vertices) != 1 || len(idle.Fixtures[0].Vertices[0]) != 4 {
+		t.Errorf("idle = %+v", idle)
+	}
+}
+
+func TestPhysicsOptionsValidate(t *testing.T) {
+	for _, bad := range []PhysicsOptions{{Parts: -1}, {Vertices: 2}, {Tolerance: -1}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v 应返回错误", bad)
+		}
+	}
+}
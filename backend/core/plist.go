@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parsePlist 解析XML格式的plist，返回的值为
+// map[string]any、[]any、string、int64、float64或bool
+func parsePlist(data []byte) (any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("plist中没有数据")
+			}
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+		return parsePlistValue(decoder, start)
+	}
+}
+
+// parsePlistValue 解析一个plist值
+func parsePlistValue(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := map[string]any{}
+		var key string
+		hasKey := false
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := decoder.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					hasKey = true
+					continue
+				}
+				if !hasKey {
+					return nil, fmt.Errorf("plist的dict中缺少key: <%s>", t.Name.Local)
+				}
+				value, err := parsePlistValue(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+				hasKey = false
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		var array []any
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				value, err := parsePlistValue(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch start.Name.Local {
+	case "string", "date", "data":
+		return text, nil
+	case "integer":
+		return strconv.ParseInt(text, 10, 64)
+	case "real":
+		return strconv.ParseFloat(text, 64)
+	}
+	return nil, fmt.Errorf("不支持的plist类型: <%s>", start.Name.Local)
+}
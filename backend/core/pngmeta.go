@@ -0,0 +1,422 @@
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// pngSignature PNG文件头
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// maxChunkLength PNG规范允许的最大数据块长度
+const maxChunkLength = 1<<31 - 1
+
+// maxInflateSize 压缩文本块解压后的最大字节数，防止解压炸弹
+const maxInflateSize = 8 << 20
+
+// 导出精灵图时写入的文本块关键字
+const (
+	MetaKeyName   = "SpriteCuter.Name"
+	MetaKeySource = "SpriteCuter.Source"
+	MetaKeyRect   = "SpriteCuter.Rect"
+	MetaKeyAtlas  = "SpriteCuter.Atlas"
+)
+
+// metaKeyPrefix 本工具写入的文本块关键字前缀
+const metaKeyPrefix = "SpriteCuter."
+
+// PNGOptions PNG编码选项
+type PNGOptions struct {
+	Compression   string            `json:"compression"`    // default、none、speed、best
+	StripMetadata bool              `json:"strip_metadata"` // 去除gamma、ICC和文本块
+	Metadata      bool              `json:"metadata"`       // 写入精灵名称、来源图集和区域
+	Text          map[string]string `json:"text"`           // 自定义文本块
+}
+
+// Validate 校验PNG编码选项
+func (o *PNGOptions) Validate() error {
+	if _, err := compressionLevel(o.Compression); err != nil {
+		return err
+	}
+	for key := range o.Text {
+		if err := checkKeyword(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PNGChunk 表示一个PNG数据块
+type PNGChunk struct {
+	Type string
+	Data []byte
+}
+
+// compressionLevel 解析压缩级别
+func compressionLevel(name string) (png.CompressionLevel, error) {
+	switch name {
+	case "", "default":
+		return png.DefaultCompression, nil
+	case "none":
+		return png.NoCompression, nil
+	case "speed":
+		return png.BestSpeed, nil
+	case "best":
+		return png.BestCompression, nil
+	}
+	return 0, fmt.Errorf("不支持的压缩级别: %s", name)
+}
+
+// isMetadataChunk 判断是否为gamma、ICC或文本等元数据块
+func isMetadataChunk(typ string) bool {
+	switch typ {
+	case "gAMA", "cHRM", "sRGB", "iCCP", "tEXt", "zTXt", "iTXt":
+		return true
+	}
+	return false
+}
+
+// isTextChunk 判断是否为文本块
+func isTextChunk(typ string) bool {
+	return typ == "tEXt" || typ == "zTXt" || typ == "iTXt"
+}
+
+// ReadPNGChunks 读取PNG中的所有数据块
+func ReadPNGChunks(r io.Reader) ([]PNGChunk, error) {
+	sig := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, sig); err != nil {
+		return nil, err
+	}
+	if string(sig) != pngSignature {
+		return nil, errors.New("不是有效的PNG文件")
+	}
+
+	var chunks []PNGChunk
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		typ := string(header[4:8])
+		if length > maxChunkLength {
+			return nil, fmt.Errorf("%q块长度超出范围: %d", typ, length)
+		}
+		// 数据和CRC共length+4字节，先按剩余字节数校验，避免按伪造的长度分配内存
+		if remaining, ok := remainingBytes(r); ok && length+4 > remaining {
+			return nil, fmt.Errorf("%q块数据不完整", typ)
+		}
+		var data bytes.Buffer
+		if _, err := io.CopyN(&data, r, length+4); err != nil {
+			return nil, fmt.Errorf("%q块数据不完整", typ)
+		}
+		chunks = append(chunks, PNGChunk{Type: typ, Data: data.Bytes()[:length]})
+		if typ == "IEND" {
+			return chunks, nil
+		}
+	}
+}
+
+// remainingBytes 返回r中剩余的字节数，无法得知时ok为false
+func remainingBytes(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err := v.Seek(current, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - current, true
+	}
+	return 0, false
+}
+
+// MetadataChunks 从数据块中筛选出gamma、ICC和文本等元数据块
+func MetadataChunks(chunks []PNGChunk) []PNGChunk {
+	var result []PNGChunk
+	for _, chunk := range chunks {
+		if isMetadataChunk(chunk.Type) {
+			result = append(result, chunk)
+		}
+	}
+	return result
+}
+
+// InheritableChunks 筛选可以继承到导出图片的元数据块
+// 来源图集自身的追溯信息和图集帧信息不再继承
+func InheritableChunks(chunks []PNGChunk) []PNGChunk {
+	var result []PNGChunk
+	for _, chunk := range chunks {
+		if !isMetadataChunk(chunk.Type) {
+			continue
+		}
+		if isTextChunk(chunk.Type) {
+			if key, _, ok := bytes.Cut(chunk.Data, []byte{0}); ok && strings.HasPrefix(string(key), metaKeyPrefix) {
+				continue
+			}
+		}
+		result = append(result, chunk)
+	}
+	return result
+}
+
+// ReadPNGText 读取PNG中的tEXt、zTXt和iTXt文本
+func ReadPNGText(r io.Reader) (map[string]string, error) {
+	chunks, err := ReadPNGChunks(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTextChunks(chunks)
+}
+
+// ParseTextChunks 解析数据块中的文本
+func ParseTextChunks(chunks []PNGChunk) (map[string]string, error) {
+	text := map[string]string{}
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case "tEXt":
+			key, value, ok := bytes.Cut(chunk.Data, []byte{0})
+			if !ok {
+				return nil, errors.New("tEXt块格式错误")
+			}
+			text[string(key)] = latin1ToString(value)
+		case "zTXt":
+			key, rest, ok := bytes.Cut(chunk.Data, []byte{0})
+			if !ok || len(rest) < 1 {
+				return nil, errors.New("zTXt块格式错误")
+			}
+			value, err := inflate(rest[1:])
+			if err != nil {
+				return nil, err
+			}
+			text[string(key)] = latin1ToString(value)
+		case "iTXt":
+			key, value, err := parseITXt(chunk.Data)
+			if err != nil {
+				return nil, err
+			}
+			text[key] = value
+		}
+	}
+	return text, nil
+}
+
+// parseITXt 解析iTXt块
+func parseITXt(data []byte) (string, string, error) {
+	key, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok || len(rest) < 2 {
+		return "", "", errors.New("iTXt块格式错误")
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+	// 跳过语言标签和翻译后的关键字
+	_, rest, ok = bytes.Cut(rest, []byte{0})
+	if !ok {
+		return "", "", errors.New("iTXt块格式错误")
+	}
+	_, rest, ok = bytes.Cut(rest, []byte{0})
+	if !ok {
+		return "", "", errors.New("iTXt块格式错误")
+	}
+	if compressed {
+		value, err := inflate(rest)
+		if err != nil {
+			return "", "", err
+		}
+		return string(key), string(value), nil
+	}
+	return string(key), string(rest), nil
+}
+
+// TextChunk 生成文本块，纯ASCII内容使用tEXt，否则使用UTF-8的iTXt
+func TextChunk(key, value string) PNGChunk {
+	if isASCII(value) {
+		data := make([]byte, 0, len(key)+1+len(value))
+		data = append(data, key...)
+		data = append(data, 0)
+		data = append(data, value...)
+		return PNGChunk{Type: "tEXt", Data: data}
+	}
+	return ITXtChunk(key, value, false)
+}
+
+// ITXtChunk 生成iTXt文本块，compress为true时使用zlib压缩
+func ITXtChunk(key, value string, compress bool) PNGChunk {
+	var buf bytes.Buffer
+	buf.WriteString(key)
+	buf.WriteByte(0)
+	if compress {
+		buf.Write([]byte{1, 0})
+	} else {
+		buf.Write([]byte{0, 0})
+	}
+	// 空语言标签和空翻译关键字
+	buf.Write([]byte{0, 0})
+	if compress {
+		zw := zlib.NewWriter(&buf)
+		zw.Write([]byte(value))
+		zw.Close()
+	} else {
+		buf.WriteString(value)
+	}
+	return PNGChunk{Type: "iTXt", Data: buf.Bytes()}
+}
+
+// SpriteMetadata 生成描述精灵来源的文本块
+func SpriteMetadata(name, source string, rect Rect) []PNGChunk {
+	return []PNGChunk{
+		TextChunk(MetaKeyName, name),
+		TextChunk(MetaKeySource, source),
+		TextChunk(MetaKeyRect, formatRect(rect)),
+	}
+}
+
+// ParseMetaRect 解析文本块中记录的区域，格式为 x,y,width,height
+func ParseMetaRect(value string) (Rect, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return Rect{}, fmt.Errorf("区域格式错误: %s", value)
+	}
+	var nums [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return Rect{}, fmt.Errorf("区域格式错误: %s", value)
+		}
+		nums[i] = n
+	}
+	return NewRect(nums[0], nums[1], nums[2], nums[3]), nil
+}
+
+// formatRect 将区域格式化为 x,y,width,height
+func formatRect(rect Rect) string {
+	return fmt.Sprintf("%d,%d,%d,%d", rect.LT.X, rect.LT.Y, rect.RT.X-rect.LT.X, rect.RB.Y-rect.RT.Y)
+}
+
+// EncodePNG 按选项编码PNG，并插入额外的数据块
+func EncodePNG(w io.Writer, img image.Image, opts PNGOptions, extra []PNGChunk) error {
+	level, err := compressionLevel(opts.Compression)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: level}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	// 没有额外数据块时直接输出
+	if len(extra) == 0 && !opts.StripMetadata {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	chunks, err := ReadPNGChunks(&buf)
+	if err != nil {
+		return err
+	}
+
+	// 颜色相关的块必须位于PLTE和IDAT之前，统一放在IHDR之后
+	var out []PNGChunk
+	for _, chunk := range chunks {
+		if opts.StripMetadata && isMetadataChunk(chunk.Type) {
+			continue
+		}
+		out = append(out, chunk)
+		if chunk.Type == "IHDR" {
+			out = append(out, extra...)
+		}
+	}
+
+	if _, err := io.WriteString(w, pngSignature); err != nil {
+		return err
+	}
+	for _, chunk := range out {
+		if err := writeChunk(w, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeChunk 写入单个数据块
+func writeChunk(w io.Writer, chunk PNGChunk) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[:4], uint32(len(chunk.Data)))
+	copy(header[4:], chunk.Type)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(chunk.Data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+
+	for _, b := range [][]byte{header, chunk.Data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkKeyword 校验文本块关键字，长度1-79且不含首尾空格
+func checkKeyword(key string) error {
+	if len(key) < 1 || len(key) > 79 || strings.TrimSpace(key) != key || strings.ContainsRune(key, 0) {
+		return fmt.Errorf("无效的文本块关键字: %q", key)
+	}
+	return nil
+}
+
+// inflate 解压zlib数据，解压后超过maxInflateSize时返回错误
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxInflateSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxInflateSize {
+		return nil, fmt.Errorf("压缩文本解压后超过%d字节", maxInflateSize)
+	}
+	return out, nil
+}
+
+// latin1ToString 将Latin-1字节转换为字符串
+func latin1ToString(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// isASCII 判断字符串是否只包含可打印ASCII字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 || (s[i] < 0x20 && s[i] != '\n') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+)
+
+// testPNG 编码一张2x2的PNG
+func testPNG(t *testing.T, opts PNGOptions, extra []PNGChunk) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := EncodePNG(&buf, img, opts, extra); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// craftedPNG 返回PNG文件头和一个声明长度为length的数据块，实际只带data
+func craftedPNG(length uint32, data []byte) []byte {
+	b := []byte(pngSignature)
+	b = binary.BigEndian.AppendUint32(b, length)
+	b = append(b, "tEXt"...)
+	return append(b, data...)
+}
+
+func TestPNGTextRoundTrip(t *testing.T) {
+	extra := append(SpriteMetadata("走路_1", "sheet.png", NewRect(1, 2, 3, 4)),
+		ITXtChunk("Comment", "压缩的文本", true),
+		TextChunk("Author", "someone"))
+	data := testPNG(t, PNGOptions{}, extra)
+
+	text, err := ReadPNGText(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		MetaKeyName:   "走路_1",
+		MetaKeySource: "sheet.png",
+		MetaKeyRect:   "1,2,3,4",
+		"Comment":     "压缩的文本",
+		"Author":      "someone",
+	}
+	for key, value := range want {
+		if text[key] != value {
+			t.Errorf("%s = %q, want %q", key, text[key], value)
+		}
+	}
+	rect, err := ParseMetaRect(text[MetaKeyRect])
+	if err != nil || rect != NewRect(1, 2, 3, 4) {
+		t.Errorf("ParseMetaRect = %v, %v", rect, err)
+	}
+}
+
+func TestEncodePNGStripMetadata(t *testing.T) {
+	data := testPNG(t, PNGOptions{StripMetadata: true}, nil)
+	chunks, err := ReadPNGChunks(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta := MetadataChunks(chunks); len(meta) != 0 {
+		t.Errorf("元数据块未去除: %v", meta)
+	}
+	if chunks[0].Type != "IHDR" || chunks[len(chunks)-1].Type != "IEND" {
+		t.Errorf("数据块顺序错误: %s ... %s", chunks[0].Type, chunks[len(chunks)-1].Type)
+	}
+}
+
+func TestInheritableChunksSkipsOwnMetadata(t *testing.T) {
+	chunks := append(SpriteMetadata("a", "b", NewRect(0, 0, 1, 1)), TextChunk("Author", "x"), PNGChunk{Type: "gAMA", Data: []byte{0, 0, 0, 1}})
+	got := InheritableChunks(chunks)
+	if len(got) != 2 || got[1].Type != "gAMA" {
+		t.Errorf("InheritableChunks = %v", got)
+	}
+}
+
+func TestReadPNGChunksRejectsBadLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		reader io.Reader
+	}{
+		{"超出规范", bytes.NewReader(craftedPNG(0xFFFFFFFF, []byte("abc")))},
+		{"超出剩余字节", bytes.NewReader(craftedPNG(1<<30, []byte("abc")))},
+		// 无法得知剩余字节数的Reader按实际读到的数据判断
+		{"数据不完整", io.MultiReader(bytes.NewReader(craftedPNG(1<<30, []byte("abc"))))},
+		{"缺少CRC", io.MultiReader(bytes.NewReader(craftedPNG(3, []byte("abc"))))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadPNGChunks(tt.reader); err == nil {
+				t.Error("应返回错误")
+			}
+		})
+	}
+}
+
+func TestInflateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(make([]byte, maxInflateSize+1))
+	zw.Close()
+	if _, err := inflate(buf.Bytes()); err == nil {
+		t.Error("超过上限应返回错误")
+	}
+
+	buf.Reset()
+	zw = zlib.NewWriter(&buf)
+	zw.Write([]byte("abc"))
+	zw.Close()
+	if out, err := inflate(buf.Bytes()); err != nil || string(out) != "abc" {
+		t.Errorf("inflate = %q, %v", out, err)
+	}
+}
+
+func TestReadPNGChunksRejectsSignature(t *testing.T) {
+	if _, err := ReadPNGChunks(bytes.NewReader([]byte("GIF89a.."))); err == nil {
+		t.Error("应返回错误")
+	}
+}
+
+func TestPNGOptionsValidate(t *testing.T) {
+	if err := (&PNGOptions{Compression: "fast"}).Validate(); err == nil {
+		t.Error("不支持的压缩级别应返回错误")
+	}
+	if err := (&PNGOptions{Text: map[string]string{" key": "v"}}).Validate(); err == nil {
+		t.Error("首尾有空格的关键字应返回错误")
+	}
+	if err := (&PNGOptions{Compression: "best", Text: map[string]string{"Author": "v"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
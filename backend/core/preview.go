@@ -0,0 +1,96 @@
+package core
+
+import (
+	_ "embed"
+	"html/template"
+	"strings"
+)
+
+//go:embed templates/preview.html
+var previewTemplate string
+
+// preview 预览页面的模板
+var preview = template.Must(template.New("preview").Parse(previewTemplate))
+
+// previewSprite 预览页面中的精灵
+type previewSprite struct {
+	Name, Class, Snippet string
+	X, Y, Width, Height  int
+}
+
+// previewAnimation 预览页面中的动画，由页面中的脚本逐帧播放
+type previewAnimation struct {
+	Name   string         `json:"name"`
+	Height int            `json:"height"`
+	Frames []previewFrame `json:"frames"`
+}
+
+// previewFrame 动画中的一帧
+type previewFrame struct {
+	X        int `json:"x"`
+	Y        int `json:"y"`
+	W        int `json:"w"`
+	H        int `json:"h"`
+	Duration int `json:"duration"`
+}
+
+// GetPreviewHTML 生成导出包中的index.html预览页面
+// 页面引用导出的CSS文件，列出每个精灵的名称、尺寸和位置并提供复制代码的按钮，
+// 按帧名称分组的动画由页面中的脚本播放。页面不依赖外部资源，解压后可直接打开
+func GetPreviewHTML(sheet Sheet, cssFile string, opts CSSOptions) (string, error) {
+	base := opts.BaseClass()
+	data := struct {
+		Title, Image, CSSFile, BaseClass string
+		Width, Height                    int
+		Sprites                          []previewSprite
+		Animations                       []previewAnimation
+	}{
+		Title:     strings.TrimSuffix(cssFile, ".css"),
+		Image:     styleImage(sheet, opts),
+		CSSFile:   cssFile,
+		BaseClass: base,
+		Width:     sheet.Width,
+		Height:    sheet.Height,
+	}
+
+	for i, frame := range sheet.Frames {
+		class := opts.ClassName(i, frame)
+		rect := frame.Rect
+		data.Sprites = append(data.Sprites, previewSprite{
+			Name:    FrameName(i, frame),
+			Class:   class,
+			Snippet: `<span class="` + base + " " + class + `"></span>`,
+			X:       rect.LT.X,
+			Y:       rect.LT.Y,
+			Width:   rect.RT.X - rect.LT.X,
+			Height:  rect.RB.Y - rect.RT.Y,
+		})
+	}
+
+	for _, animation := range GroupAnimations(sheet.Frames) {
+		if len(animation.Frames) < 2 {
+			continue
+		}
+		a := previewAnimation{Name: animation.Name}
+		for _, index := range animation.Frames {
+			frame := sheet.Frames[index]
+			rect := frame.Rect
+			f := previewFrame{
+				X:        rect.LT.X,
+				Y:        rect.LT.Y,
+				W:        rect.RT.X - rect.LT.X,
+				H:        rect.RB.Y - rect.RT.Y,
+				Duration: frameDuration(frame, 100),
+			}
+			a.Height = max(a.Height, f.H)
+			a.Frames = append(a.Frames, f)
+		}
+		data.Animations = append(data.Animations, a)
+	}
+
+	var b strings.Builder
+	if err := preview.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
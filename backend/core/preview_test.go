@@ -0,0 +1,69 @@
+package core
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// previewAnimations 返回页面脚本中的动画数据
+func previewAnimations(t *testing.T, html string) string {
+	t.Helper()
+	m := regexp.MustCompile(`var animations = (.*) \|\| \[\];`).FindStringSubmatch(html)
+	if m == nil {
+		t.Fatal("缺少动画数据")
+	}
+	return strings.TrimSpace(m[1])
+}
+
+func TestGetPreviewHTML(t *testing.T) {
+	frames := append(atlasFrames(), Frame{Name: `<b>"x"</b>`, Rect: NewRect(0, 8, 4, 4)})
+	frames[1].Duration = 80
+	sheet := testSheet(32, 16, frames...)
+	html, err := GetPreviewHTML(sheet, "hero.css", CSSOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<link rel="stylesheet" href="hero.css">`,
+		`共 5 个精灵，1 组动画`,
+		`<span class="sprite walk_1"></span>`,
+		`&lt;b&gt;&#34;x&#34;&lt;/b&gt;`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("缺少 %q", want)
+		}
+	}
+	if strings.Contains(html, `<b>"x"</b>`) {
+		t.Error("帧名称未转义")
+	}
+	// 页面不引用外部资源
+	if regexp.MustCompile(`(src|href)="(https?:)?//`).MatchString(html) {
+		t.Error("预览页面引用了外部资源")
+	}
+
+	var animations []previewAnimation
+	if err := json.Unmarshal([]byte(previewAnimations(t, html)), &animations); err != nil {
+		t.Fatalf("动画数据不是合法的JSON: %v", err)
+	}
+	if len(animations) != 1 || animations[0].Name != "walk" || len(animations[0].Frames) != 2 {
+		t.Fatalf("动画 = %+v", animations)
+	}
+	walk := animations[0]
+	if walk.Height != 7 || walk.Frames[0].Duration != 80 || walk.Frames[1].Duration != 100 {
+		t.Errorf("动画 = %+v", walk)
+	}
+}
+
+func TestGetPreviewHTMLWithoutAnimations(t *testing.T) {
+	sheet := testSheet(16, 8, FramesFromRects([]Rect{NewRect(0, 0, 4, 4), NewRect(8, 0, 4, 4)})...)
+	html, err := GetPreviewHTML(sheet, "a.css", CSSOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := previewAnimations(t, html); data != "null" {
+		t.Errorf("没有动画时动画数据应为空: %s", data)
+	}
+}
@@ -0,0 +1,414 @@
+package core
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// 量化算法
+const (
+	QuantizeMedianCut = "mediancut"
+	QuantizeOctree    = "octree"
+)
+
+// QuantizeOptions 调色板量化选项
+type QuantizeOptions struct {
+	Colors int    `json:"colors"` // 调色板颜色数，2-256
+	Method string `json:"method"` // mediancut 或 octree
+	Dither bool   `json:"dither"` // 是否使用Floyd–Steinberg抖动
+	Shared bool   `json:"shared"` // 整张图集共用一个调色板
+}
+
+// Validate 校验并补全量化选项
+func (o *QuantizeOptions) Validate() error {
+	if o.Colors == 0 {
+		o.Colors = 256
+	}
+	if o.Colors < 2 || o.Colors > 256 {
+		return fmt.Errorf("颜色数必须在2到256之间: %d", o.Colors)
+	}
+	if o.Method == "" {
+		o.Method = QuantizeMedianCut
+	}
+	if o.Method != QuantizeMedianCut && o.Method != QuantizeOctree {
+		return fmt.Errorf("不支持的量化算法: %s", o.Method)
+	}
+	return nil
+}
+
+// colorCount 直方图中的一种颜色及其像素数
+type colorCount struct {
+	c [4]uint8
+	n int
+}
+
+// BuildPalette 统计指定区域内的颜色并生成不超过n色的调色板
+// 存在全透明像素时，调色板第0项固定为透明色
+func BuildPalette(img image.Image, rects []Rect, n int, method string) color.Palette {
+	hist := map[[4]uint8]int{}
+	hasAlpha := false
+	bounds := img.Bounds()
+
+	regions := make([]image.Rectangle, 0, len(rects))
+	for _, rect := range rects {
+		regions = append(regions, image.Rect(rect.LT.X, rect.LT.Y, rect.RB.X, rect.RB.Y).Add(bounds.Min).Intersect(bounds))
+	}
+	if len(rects) == 0 {
+		regions = append(regions, bounds)
+	}
+
+	for _, r := range regions {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				if c.A == 0 {
+					hasAlpha = true
+					continue
+				}
+				hist[[4]uint8{c.R, c.G, c.B, c.A}]++
+			}
+		}
+	}
+
+	colors := make([]colorCount, 0, len(hist))
+	for c, cnt := range hist {
+		colors = append(colors, colorCount{c: c, n: cnt})
+	}
+	// 保证结果稳定
+	sort.Slice(colors, func(i, j int) bool {
+		return packColor(colors[i].c) < packColor(colors[j].c)
+	})
+
+	limit := n
+	var palette color.Palette
+	if hasAlpha {
+		palette = append(palette, color.NRGBA{})
+		limit--
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	var quantized [][4]uint8
+	if len(colors) <= limit {
+		for _, c := range colors {
+			quantized = append(quantized, c.c)
+		}
+	} else if method == QuantizeOctree {
+		quantized = octreeQuantize(colors, limit)
+	} else {
+		quantized = medianCut(colors, limit)
+	}
+
+	for _, c := range quantized {
+		palette = append(palette, color.NRGBA{R: c[0], G: c[1], B: c[2], A: c[3]})
+	}
+	if len(palette) == 0 {
+		palette = append(palette, color.NRGBA{})
+	}
+	return palette
+}
+
+// Quantize 将图像映射到给定调色板
+func Quantize(img image.Image, palette color.Palette, dither bool) *image.Paletted {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+
+	cache := map[[4]uint8]uint8{}
+	lookup := func(c [4]uint8) uint8 {
+		if idx, ok := cache[c]; ok {
+			return idx
+		}
+		idx := nearestIndex(palette, c)
+		cache[c] = idx
+		return idx
+	}
+
+	if !dither {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+				dst.Pix[dst.PixOffset(x, y)] = lookup([4]uint8{c.R, c.G, c.B, c.A})
+			}
+		}
+		return dst
+	}
+
+	// Floyd–Steinberg抖动，误差按7/16、3/16、5/16、1/16扩散
+	cur := make([][4]float64, width+2)
+	next := make([][4]float64, width+2)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			want := [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+
+			// 全透明像素不参与误差扩散，避免边缘出现杂点
+			if c.A == 0 {
+				dst.Pix[dst.PixOffset(x, y)] = lookup([4]uint8{})
+				continue
+			}
+
+			var target [4]uint8
+			for i := range want {
+				target[i] = clampUint8(want[i] + cur[x+1][i])
+			}
+			idx := lookup(target)
+			dst.Pix[dst.PixOffset(x, y)] = idx
+
+			got := color.NRGBAModel.Convert(palette[idx]).(color.NRGBA)
+			actual := [4]float64{float64(got.R), float64(got.G), float64(got.B), float64(got.A)}
+			for i := range want {
+				e := float64(target[i]) - actual[i]
+				cur[x+2][i] += e * 7 / 16
+				next[x][i] += e * 3 / 16
+				next[x+1][i] += e * 5 / 16
+				next[x+2][i] += e * 1 / 16
+			}
+		}
+		cur, next = next, cur
+		for i := range next {
+			next[i] = [4]float64{}
+		}
+	}
+	return dst
+}
+
+// nearestIndex 在调色板中查找最接近的颜色
+func nearestIndex(palette color.Palette, c [4]uint8) uint8 {
+	best, bestDist := 0, -1
+	for i, p := range palette {
+		pc := color.NRGBAModel.Convert(p).(color.NRGBA)
+		if c[3] == 0 && pc.A == 0 {
+			return uint8(i)
+		}
+		dr := int(c[0]) - int(pc.R)
+		dg := int(c[1]) - int(pc.G)
+		db := int(c[2]) - int(pc.B)
+		da := int(c[3]) - int(pc.A)
+		dist := dr*dr + dg*dg + db*db + da*da*2
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return uint8(best)
+}
+
+// medianCut 中位切分算法
+func medianCut(colors []colorCount, n int) [][4]uint8 {
+	boxes := [][]colorCount{colors}
+
+	for len(boxes) < n {
+		// 选择范围最大的可切分盒子
+		bestBox, bestChannel, bestRange := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			channel, r := widestChannel(box)
+			if r > bestRange {
+				bestBox, bestChannel, bestRange = i, channel, r
+			}
+		}
+		if bestBox < 0 {
+			break
+		}
+
+		box := boxes[bestBox]
+		sort.Slice(box, func(i, j int) bool {
+			return box[i].c[bestChannel] < box[j].c[bestChannel]
+		})
+
+		// 按像素数加权求中位
+		total := 0
+		for _, c := range box {
+			total += c.n
+		}
+		split, acc := 1, 0
+		for i, c := range box {
+			acc += c.n
+			if acc*2 >= total {
+				split = i + 1
+				break
+			}
+		}
+		if split >= len(box) {
+			split = len(box) - 1
+		}
+
+		boxes[bestBox] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	result := make([][4]uint8, 0, len(boxes))
+	for _, box := range boxes {
+		result = append(result, averageColor(box))
+	}
+	return result
+}
+
+// widestChannel 返回盒子中取值范围最大的通道
+func widestChannel(box []colorCount) (int, int) {
+	lo := [4]uint8{255, 255, 255, 255}
+	var hi [4]uint8
+	for _, c := range box {
+		for i := 0; i < 4; i++ {
+			if c.c[i] < lo[i] {
+				lo[i] = c.c[i]
+			}
+			if c.c[i] > hi[i] {
+				hi[i] = c.c[i]
+			}
+		}
+	}
+	channel, r := 0, -1
+	for i := 0; i < 4; i++ {
+		if int(hi[i])-int(lo[i]) > r {
+			channel, r = i, int(hi[i])-int(lo[i])
+		}
+	}
+	return channel, r
+}
+
+// averageColor 按像素数加权计算平均颜色
+func averageColor(box []colorCount) [4]uint8 {
+	var sum [4]int
+	total := 0
+	for _, c := range box {
+		for i := 0; i < 4; i++ {
+			sum[i] += int(c.c[i]) * c.n
+		}
+		total += c.n
+	}
+	var avg [4]uint8
+	if total == 0 {
+		return avg
+	}
+	for i := 0; i < 4; i++ {
+		avg[i] = uint8((sum[i] + total/2) / total)
+	}
+	return avg
+}
+
+// octreeNode 八叉树节点，按RGBA各取一位共16个子节点
+type octreeNode struct {
+	children [16]*octreeNode
+	sum      [4]int
+	count    int
+	leaf     bool
+}
+
+// octreeQuantize 八叉树量化算法
+func octreeQuantize(colors []colorCount, n int) [][4]uint8 {
+	const depth = 8
+	root := &octreeNode{}
+	levels := make([][]*octreeNode, depth)
+
+	for _, c := range colors {
+		node := root
+		for level := 0; level < depth; level++ {
+			shift := 7 - level
+			idx := 0
+			for i := 0; i < 4; i++ {
+				idx |= int(c.c[i]>>shift&1) << i
+			}
+			if node.children[idx] == nil {
+				node.children[idx] = &octreeNode{}
+				if level < depth-1 {
+					levels[level] = append(levels[level], node.children[idx])
+				}
+			}
+			node = node.children[idx]
+		}
+		node.leaf = true
+		for i := 0; i < 4; i++ {
+			node.sum[i] += int(c.c[i]) * c.n
+		}
+		node.count += c.n
+	}
+
+	leaves := len(colors)
+	// 从最深层开始合并像素数最少的节点
+	for level := depth - 2; level >= 0 && leaves > n; level-- {
+		nodes := levels[level]
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return subtreeCount(nodes[i]) < subtreeCount(nodes[j])
+		})
+		for _, node := range nodes {
+			if leaves <= n {
+				break
+			}
+			merged := 0
+			for i, child := range node.children {
+				if child == nil {
+					continue
+				}
+				for k := 0; k < 4; k++ {
+					node.sum[k] += child.sum[k]
+				}
+				node.count += child.count
+				node.children[i] = nil
+				merged++
+			}
+			node.leaf = true
+			leaves -= merged - 1
+		}
+	}
+
+	var result []colorCount
+	collectLeaves(root, &result)
+	if len(result) > n {
+		// 颜色数少于16时根节点无法合并，退回中位切分
+		return medianCut(result, n)
+	}
+	palette := make([][4]uint8, 0, len(result))
+	for _, c := range result {
+		palette = append(palette, c.c)
+	}
+	return palette
+}
+
+// subtreeCount 统计子树的像素数
+func subtreeCount(node *octreeNode) int {
+	total := node.count
+	for _, child := range node.children {
+		if child != nil {
+			total += subtreeCount(child)
+		}
+	}
+	return total
+}
+
+// collectLeaves 收集所有叶子节点的平均颜色
+func collectLeaves(node *octreeNode, result *[]colorCount) {
+	if node.leaf && node.count > 0 {
+		var c [4]uint8
+		for i := 0; i < 4; i++ {
+			c[i] = uint8((node.sum[i] + node.count/2) / node.count)
+		}
+		*result = append(*result, colorCount{c: c, n: node.count})
+	}
+	for _, child := range node.children {
+		if child != nil {
+			collectLeaves(child, result)
+		}
+	}
+}
+
+// packColor 将颜色打包为整数用于排序
+func packColor(c [4]uint8) uint32 {
+	return uint32(c[0])<<24 | uint32(c[1])<<16 | uint32(c[2])<<8 | uint32(c[3])
+}
+
+// clampUint8 将浮点值限制在0-255
+func clampUint8(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// gradient 生成带透明列的渐变图，颜色数远超256
+func gradient(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 1; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 4), G: uint8(y * 4), B: uint8(x + y), A: 255})
+		}
+	}
+	return img
+}
+
+func TestBuildPaletteLimitsColors(t *testing.T) {
+	img := gradient(64, 64)
+	for _, method := range []string{QuantizeMedianCut, QuantizeOctree} {
+		t.Run(method, func(t *testing.T) {
+			palette := BuildPalette(img, nil, 16, method)
+			if len(palette) > 16 {
+				t.Errorf("调色板有 %d 色, want <= 16", len(palette))
+			}
+			if _, _, _, a := palette[0].RGBA(); a != 0 {
+				t.Error("存在透明像素时第0项应为透明色")
+			}
+		})
+	}
+}
+
+func TestBuildPaletteKeepsFewColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	img.Set(1, 0, color.NRGBA{B: 255, A: 128})
+	palette := BuildPalette(img, nil, 256, QuantizeMedianCut)
+	if len(palette) != 2 {
+		t.Fatalf("调色板 = %v, want 2色", palette)
+	}
+	out := Quantize(img, palette, false)
+	for x := 0; x < 2; x++ {
+		if out.At(x, 0) != color.Color(img.NRGBAAt(x, 0)) {
+			t.Errorf("像素 %d = %v, want %v", x, out.At(x, 0), img.NRGBAAt(x, 0))
+		}
+	}
+}
+
+func TestBuildPaletteOnlyCountsRects(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	img.Set(3, 0, color.NRGBA{G: 255, A: 255})
+	palette := BuildPalette(img, []Rect{NewRect(0, 0, 1, 1)}, 256, QuantizeMedianCut)
+	if len(palette) != 1 {
+		t.Errorf("调色板 = %v, want 只有区域内的1色", palette)
+	}
+}
+
+func TestQuantizeDitherKeepsTransparency(t *testing.T) {
+	img := gradient(16, 16)
+	out := Quantize(img, BuildPalette(img, nil, 4, QuantizeMedianCut), true)
+	for y := 0; y < 16; y++ {
+		if _, _, _, a := out.At(0, y).RGBA(); a != 0 {
+			t.Fatalf("透明像素 (0,%d) 抖动后不透明", y)
+		}
+	}
+}
+
+func TestEncodeSpriteQuantize(t *testing.T) {
+	img := gradient(32, 32)
+	var buf bytes.Buffer
+	opts := SaveOptions{Quantize: &QuantizeOptions{Colors: 8, Method: QuantizeOctree}}
+	result, err := EncodeSprite(&buf, img, Frame{Rect: NewRect(0, 0, 32, 32)}, 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OriginalSize == 0 || result.Size != int64(buf.Len()) {
+		t.Errorf("SaveResult = %+v, 实际 %d 字节", result, buf.Len())
+	}
+	out, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := out.(*image.Paletted); !ok || len(p.Palette) > 8 {
+		t.Errorf("量化后应为不超过8色的调色板图, got %T", out)
+	}
+}
+
+func TestQuantizeOptionsValidate(t *testing.T) {
+	opts := QuantizeOptions{}
+	if err := opts.Validate(); err != nil || opts.Colors != 256 || opts.Method != QuantizeMedianCut {
+		t.Errorf("默认值 = %+v, %v", opts, err)
+	}
+	for _, bad := range []QuantizeOptions{{Colors: 1}, {Colors: 257}, {Method: "kmeans"}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v 应返回错误", bad)
+		}
+	}
+}
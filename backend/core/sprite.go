@@ -1,11 +1,11 @@
 package core
 
 import (
-	"fmt"
 	"image"
-	"image/png"
+	"image/color"
+	"io"
 	"math"
-	"os"
+	"sort"
 )
 
 // Point 表示一个二维坐标点
@@ -18,23 +18,23 @@ type Rect struct {
 	LT, LB, RT, RB Point // 左上、左下、右上、右下
 }
 
+// NewRect 根据左上角坐标和宽高创建矩形
+func NewRect(x, y, width, height int) Rect {
+	return Rect{
+		LT: Point{X: x, Y: y},
+		LB: Point{X: x, Y: y + height},
+		RT: Point{X: x + width, Y: y},
+		RB: Point{X: x + width, Y: y + height},
+	}
+}
+
 // GetSprites 检测图像中的所有精灵
 func GetSprites(img image.Image) []Rect {
 	bounds := img.Bounds()
 	imgWidth, imgHeight := bounds.Dx(), bounds.Dy()
 
-	// 创建像素数据副本
-	data := make([]uint8, imgWidth*imgHeight*4)
-	for y := 0; y < imgHeight; y++ {
-		for x := 0; x < imgWidth; x++ {
-			r, g, b, a := img.At(x, y).RGBA()
-			idx := (y*imgWidth + x) * 4
-			data[idx] = uint8(r >> 8)
-			data[idx+1] = uint8(g >> 8)
-			data[idx+2] = uint8(b >> 8)
-			data[idx+3] = uint8(a >> 8)
-		}
-	}
+	// 创建alpha数据副本，检测只依赖透明度
+	data := alphaData(img)
 
 	var spritesArray []Rect
 	contourVector := marchingSquares(data, imgHeight, imgWidth)
@@ -67,76 +67,153 @@ func GetSprites(img image.Image) []Rect {
 	return spritesArray
 }
 
-// GetCSS 生成CSS样式
-func GetCSS(spritesArray []Rect, pngName string) string {
-	css := ".sprite {display:inline-block; overflow:hidden; background-repeat: no-repeat;background-image:url(" + pngName + ");}"
-	for i, rect := range spritesArray {
-		css += getSpriteCSS(fmt.Sprintf("sprite%d", i), rect)
+// SheetOptions 复制图集时的选项
+type SheetOptions struct {
+	EmbedAtlas bool `json:"embed_atlas"` // 将帧信息写入图集，再次上传时按其中的区域切割
+}
+
+// EncodeSheet 编码整张图集，atlasJson不为空时将GetJson生成的帧信息写入iTXt块
+func EncodeSheet(w io.Writer, img image.Image, atlasJson string, opts SaveOptions) error {
+	var chunks []PNGChunk
+	if !opts.PNG.StripMetadata {
+		chunks = append(chunks, InheritableChunks(opts.Chunks)...)
 	}
-	return css
+	if atlasJson != "" {
+		chunks = append(chunks, ITXtChunk(MetaKeyAtlas, atlasJson, true))
+	}
+
+	return EncodePNG(w, img, opts.PNG, chunks)
 }
 
-// GetJson 生成JSON样式
-func GetJson(spritesArray []Rect, pngName string) string {
-	imgWidth := 0
-	imgHeight := 0
-	json := fmt.Sprintf(`{"sprite":{
-		"width":%d,
-		"height":%d,
-		"image":"%s",
-		"frames":[`, imgWidth, imgHeight, pngName)
-	for i, rect := range spritesArray {
-		json += getSpriteJson(fmt.Sprintf("sprite%d", i), rect)
+// EmbeddedFrames 读取EncodeSheet写入图集的帧信息，未嵌入时ok为false
+func EmbeddedFrames(chunks []PNGChunk) (frames []Frame, ok bool, err error) {
+	text, err := ParseTextChunks(chunks)
+	if err != nil {
+		return nil, false, err
+	}
+	atlas, ok := text[MetaKeyAtlas]
+	if !ok {
+		return nil, false, nil
 	}
-	json = json[:len(json)-1] + "]}}"
-	return json
+	frames, err = ParseJson([]byte(atlas))
+	if err != nil {
+		return nil, false, err
+	}
+	return frames, true, nil
 }
 
-// SaveSprite 保存切割后的精灵图
-func SaveSprite(img image.Image, rect Rect, outDir string, index int) error {
+// SaveOptions 保存精灵图的选项
+type SaveOptions struct {
+	Quantize *QuantizeOptions // 为nil时不量化
+	Palette  color.Palette    // 共享调色板，Quantize.Shared时使用
+	PNG      PNGOptions       // PNG编码选项
+	Source   string           // 来源图集名称
+	Chunks   []PNGChunk       // 从来源图集继承的元数据块
+}
+
+// SaveResult 保存精灵图的结果
+type SaveResult struct {
+	Size         int64 // 输出文件字节数
+	OriginalSize int64 // 未量化时的字节数，仅在量化时统计
+}
+
+// EncodeSprite 编码切割后的精灵图
+// 输出图像沿用源图的颜色模型：调色板图保持原调色板，16位图保持16位；
+// 旋转存放的帧还原为原方向，去除透明边的帧还原为原始尺寸
+func EncodeSprite(w io.Writer, img image.Image, frame Frame, index int, opts SaveOptions) (SaveResult, error) {
+	var result SaveResult
+	rect := frame.Rect
+	newImg := frameImage(img, frame)
+
+	// 量化为调色板图
+	if q := opts.Quantize; q != nil {
+		original, err := encodedSize(newImg, opts.PNG)
+		if err != nil {
+			return result, err
+		}
+		result.OriginalSize = original
+
+		palette := opts.Palette
+		if palette == nil {
+			palette = BuildPalette(newImg, nil, q.Colors, q.Method)
+		}
+		newImg = Quantize(newImg, palette, q.Dither)
+	}
+
+	counter := &countingWriter{w: w}
+	chunks := spriteChunks(FrameName(index, frame), rect, opts)
+	if err := EncodePNG(counter, newImg, opts.PNG, chunks); err != nil {
+		return result, err
+	}
+	result.Size = counter.n
+	return result, nil
+}
+
+// frameImage 裁剪出帧的图像，还原旋转和透明边
+func frameImage(img image.Image, frame Frame) image.Image {
+	rect := frame.Rect
 	width := int(math.Max(1, float64(rect.RT.X-rect.LT.X)))
 	height := int(math.Max(1, float64(rect.RB.Y-rect.RT.Y)))
 
-	// 创建新的图像
-	newImg := image.NewRGBA(image.Rect(0, 0, width, height))
-
 	// 复制像素数据
 	srcX := int(math.Max(0, float64(rect.LT.X)))
 	srcY := int(math.Max(0, float64(rect.LT.Y)))
-	copyWidth := int(math.Min(float64(width), float64(img.Bounds().Dx()-srcX)))
-	copyHeight := int(math.Min(float64(height), float64(img.Bounds().Dy()-srcY)))
+	newImg := cropImage(img, srcX, srcY, width, height)
+
+	// 还原旋转
+	switch frame.Rotation {
+	case 90:
+		newImg = rotateCCW(newImg)
+	case 270:
+		newImg = rotateCW(newImg)
+	}
 
-	for y := 0; y < copyHeight; y++ {
-		for x := 0; x < copyWidth; x++ {
-			newImg.Set(x, y, img.At(srcX+x, srcY+y))
-		}
+	// 还原透明边
+	if frame.Trimmed {
+		sourceW, sourceH := frame.SourceSize()
+		newImg = padImage(newImg, sourceW, sourceH, frame.OffsetX, frame.OffsetY)
 	}
+	return newImg
+}
 
-	// 保存文件
-	filename := fmt.Sprintf("export/%s/%s%d.png", outDir, outDir, index)
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+// spriteChunks 汇总写入精灵图的额外数据块
+func spriteChunks(name string, rect Rect, opts SaveOptions) []PNGChunk {
+	var chunks []PNGChunk
+	if !opts.PNG.StripMetadata {
+		chunks = append(chunks, InheritableChunks(opts.Chunks)...)
+	}
+	if opts.PNG.Metadata {
+		chunks = append(chunks, SpriteMetadata(name, opts.Source, rect)...)
 	}
-	defer file.Close()
 
-	return png.Encode(file, newImg)
+	keys := make([]string, 0, len(opts.PNG.Text))
+	for key := range opts.PNG.Text {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		chunks = append(chunks, TextChunk(key, opts.PNG.Text[key]))
+	}
+	return chunks
+}
+
+// encodedSize 计算图像编码为PNG后的字节数
+func encodedSize(img image.Image, opts PNGOptions) (int64, error) {
+	counter := &countingWriter{w: io.Discard}
+	err := EncodePNG(counter, img, PNGOptions{Compression: opts.Compression}, nil)
+	return counter.n, err
 }
 
-// getSpriteCSS 生成单个精灵的CSS样式
-func getSpriteCSS(spriteName string, rect Rect) string {
-	width := rect.RT.X - rect.LT.X
-	height := rect.RB.Y - rect.RT.Y
-	return fmt.Sprintf(".%s {width:%dpx; height:%dpx; background-position: %dpx %dpx}",
-		spriteName, width, height, -rect.LT.X, -rect.LT.Y)
+// countingWriter 统计写入字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
 }
 
-// getSpriteJson 生成单个精灵的JSON样式
-func getSpriteJson(spriteName string, rect Rect) string {
-	width := rect.RT.X - rect.LT.X
-	height := rect.RB.Y - rect.RT.Y
-	return fmt.Sprintf(`{"name":"%s","x":%d,"y":%d,"width":%d,"height":%d},`,
-		spriteName, -rect.LT.X, -rect.LT.Y, width, height)
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
 
 // marchingSquares 实现marching squares算法检测轮廓
@@ -224,7 +301,6 @@ func getStartingPixel(data []uint8, height, width int) *Point {
 		for x := 0; x < width; x++ {
 			idx := (y*width + x) * 4
 			if idx+3 < len(data) && data[idx+3] > 0 {
-				fmt.Printf("起始点: %d %d\n", x, y)
 				return &Point{X: x, Y: y}
 			}
 		}
@@ -274,4 +350,4 @@ func getRect(points []Point) Rect {
 		RT: Point{X: maxX, Y: minY},
 		RB: Point{X: maxX, Y: maxY},
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEmbeddedFramesRoundTrip(t *testing.T) {
+	frames := atlasFrames()
+	sheet := testSheet(32, 16, frames...)
+	var buf bytes.Buffer
+	if err := EncodeSheet(&buf, sheet.img, GetJson(sheet, false), SaveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := ReadPNGChunks(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := EmbeddedFrames(chunks)
+	if err != nil || !ok {
+		t.Fatalf("EmbeddedFrames = %v, %v", ok, err)
+	}
+	assertFrames(t, got, frames)
+}
+
+func TestEmbeddedFramesMissing(t *testing.T) {
+	chunks := []PNGChunk{TextChunk("Author", "someone")}
+	if frames, ok, err := EmbeddedFrames(chunks); ok || err != nil || frames != nil {
+		t.Errorf("EmbeddedFrames = %v, %v, %v", frames, ok, err)
+	}
+}
+
+func TestEncodeSheetReplacesEmbeddedAtlas(t *testing.T) {
+	// 再次导出时不继承来源图集中旧的帧信息，只保留新写入的
+	old := ITXtChunk(MetaKeyAtlas, `{"frames":[]}`, true)
+	sheet := testSheet(32, 16, atlasFrames()...)
+	var buf bytes.Buffer
+	opts := SaveOptions{Chunks: []PNGChunk{old, TextChunk("Author", "someone")}}
+	if err := EncodeSheet(&buf, sheet.img, GetJson(sheet, false), opts); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := ReadPNGChunks(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := ParseTextChunks(chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text["Author"] != "someone" {
+		t.Error("未继承来源图集的文本块")
+	}
+	frames, _, err := EmbeddedFrames(chunks)
+	if err != nil || len(frames) != len(sheet.Frames) {
+		t.Errorf("嵌入 %d 个帧, want %d (%v)", len(frames), len(sheet.Frames), err)
+	}
+}
+
+func TestEncodeSheetStripMetadata(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	var buf bytes.Buffer
+	opts := SaveOptions{PNG: PNGOptions{StripMetadata: true}, Chunks: []PNGChunk{TextChunk("Author", "someone")}}
+	if err := EncodeSheet(&buf, sheet.img, "", opts); err != nil {
+		t.Fatal(err)
+	}
+	text, err := ReadPNGText(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(text) != 0 {
+		t.Errorf("去除元数据后仍有文本块: %v", text)
+	}
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Starling/Sparrow TextureAtlas XML 导出格式
+const StarlingXML = "starling-xml"
+
+// GetStarlingXML 生成Starling和Sparrow使用的TextureAtlas XML
+// Starling的rotated表示显示时逆时针旋转90度，即在图集中顺时针旋转存放，
+// 有逆时针旋转存放的帧时返回错误。重复的帧名称加上序号
+func GetStarlingXML(sheet Sheet) (string, error) {
+	if err := checkClockwise(sheet.Frames); err != nil {
+		return "", err
+	}
+	names := frameNames(sheet.Frames)
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, "<TextureAtlas imagePath=\"%s\" width=\"%d\" height=\"%d\">\n", xmlEscape(sheet.Image), sheet.Width, sheet.Height)
+	for i, frame := range sheet.Frames {
+		// width和height为在图集中占用的区域
+		fmt.Fprintf(&b, "\t<SubTexture name=\"%s\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"",
+			xmlEscape(names[i]), frame.Rect.LT.X, frame.Rect.LT.Y,
+			frame.Rect.RT.X-frame.Rect.LT.X, frame.Rect.RB.Y-frame.Rect.RT.Y)
+		if frame.Trimmed {
+			// frameX和frameY为原图相对裁剪区域的偏移，取负值
+			sourceW, sourceH := frame.SourceSize()
+			fmt.Fprintf(&b, " frameX=\"%d\" frameY=\"%d\" frameWidth=\"%d\" frameHeight=\"%d\"",
+				-frame.OffsetX, -frame.OffsetY, sourceW, sourceH)
+		}
+		if frame.Rotated() {
+			b.WriteString(` rotated="true"`)
+		}
+		// 锚点以像素为单位
+		if frame.Pivot != nil {
+			sourceW, sourceH := frame.SourceSize()
+			fmt.Fprintf(&b, " pivotX=\"%s\" pivotY=\"%s\"",
+				formatFloat(frame.Pivot.X*float64(sourceW)), formatFloat(frame.Pivot.Y*float64(sourceH)))
+		}
+		b.WriteString("/>\n")
+	}
+	b.WriteString("</TextureAtlas>\n")
+	return b.String(), nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+// starlingAtlas Starling TextureAtlas XML的结构
+type starlingAtlas struct {
+	ImagePath   string `xml:"imagePath,attr"`
+	SubTextures []struct {
+		Name        string   `xml:"name,attr"`
+		X           int      `xml:"x,attr"`
+		Y           int      `xml:"y,attr"`
+		Width       int      `xml:"width,attr"`
+		Height      int      `xml:"height,attr"`
+		FrameX      int      `xml:"frameX,attr"`
+		FrameY      int      `xml:"frameY,attr"`
+		FrameWidth  int      `xml:"frameWidth,attr"`
+		FrameHeight int      `xml:"frameHeight,attr"`
+		Rotated     bool     `xml:"rotated,attr"`
+		PivotX      *float64 `xml:"pivotX,attr"`
+		PivotY      *float64 `xml:"pivotY,attr"`
+	} `xml:"SubTexture"`
+}
+
+// parseStarling 按Starling的规则将XML还原为帧
+func parseStarling(t *testing.T, data string) (starlingAtlas, []Frame) {
+	t.Helper()
+	var atlas starlingAtlas
+	if err := xml.Unmarshal([]byte(data), &atlas); err != nil {
+		t.Fatal(err)
+	}
+	frames := make([]Frame, len(atlas.SubTextures))
+	for i, st := range atlas.SubTextures {
+		frame := Frame{Name: st.Name, Rect: NewRect(st.X, st.Y, st.Width, st.Height)}
+		if st.Rotated {
+			frame.Rotation = 90
+		}
+		if st.FrameWidth > 0 {
+			frame.Trimmed = true
+			frame.SourceW, frame.SourceH = st.FrameWidth, st.FrameHeight
+			frame.OffsetX, frame.OffsetY = -st.FrameX, -st.FrameY
+		}
+		frames[i] = frame
+	}
+	return atlas, frames
+}
+
+func TestStarlingXMLRoundTrip(t *testing.T) {
+	want := atlasFrames()
+	sheet := testSheet(32, 8, want...)
+	data, err := GetStarlingXML(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atlas, frames := parseStarling(t, data)
+	if atlas.ImagePath != "sheet.png" {
+		t.Errorf("imagePath = %s", atlas.ImagePath)
+	}
+	assertFrames(t, frames, want)
+	// 锚点以原始尺寸中的像素为单位
+	jump := atlas.SubTextures[3]
+	if jump.PivotX == nil || *jump.PivotX != 2 || *jump.PivotY != 6 {
+		t.Errorf("锚点 = %v, %v", jump.PivotX, jump.PivotY)
+	}
+}
+
+func TestStarlingXMLDuplicateNames(t *testing.T) {
+	sheet := testSheet(16, 8,
+		Frame{Name: "a&b", Rect: NewRect(0, 0, 4, 4)},
+		Frame{Name: "a&b", Rect: NewRect(8, 0, 4, 4)},
+	)
+	data, err := GetStarlingXML(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, frames := parseStarling(t, data)
+	if len(frames) != 2 || frames[0].Name != "a&b" || frames[1].Name != "a&b_2" {
+		t.Errorf("帧名称 = %+v", frames)
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 样式预处理器和Tailwind导出格式
+const (
+	SCSS     = "scss"
+	Less     = "less"
+	Tailwind = "tailwind"
+)
+
+// styleSprite 样式导出中的一个精灵
+type styleSprite struct {
+	Class               string
+	X, Y, Width, Height int
+}
+
+// styleSprites 按CSS导出的命名规则返回精灵
+func styleSprites(sheet Sheet, opts CSSOptions) []styleSprite {
+	sprites := make([]styleSprite, 0, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		rect := frame.Rect
+		sprites = append(sprites, styleSprite{
+			Class:  opts.ClassName(i, frame),
+			X:      rect.LT.X,
+			Y:      rect.LT.Y,
+			Width:  rect.RT.X - rect.LT.X,
+			Height: rect.RB.Y - rect.RT.Y,
+		})
+	}
+	return sprites
+}
+
+// styleImage 返回样式中引用的图集url
+func styleImage(sheet Sheet, opts CSSOptions) string {
+	if opts.Image != "" {
+		return opts.Image
+	}
+	return sheet.Image
+}
+
+// GetSCSS 生成SCSS，包含精灵名称到位置和尺寸的map，以及按名称引用精灵的mixin
+// map的键为CSS导出中的类名，使用方式为 @include sprite("walk_01");
+func GetSCSS(sheet Sheet, opts CSSOptions) string {
+	var b strings.Builder
+	base := opts.BaseClass()
+	b.WriteString("@use \"sass:map\";\n\n")
+	fmt.Fprintf(&b, "$%s-image: %s;\n\n", base, cssString(styleImage(sheet, opts)))
+	if len(sheet.Frames) == 0 {
+		fmt.Fprintf(&b, "$%s-map: ();\n\n", base)
+	} else {
+		fmt.Fprintf(&b, "$%s-map: (\n", base)
+		for _, sprite := range styleSprites(sheet, opts) {
+			fmt.Fprintf(&b, "  %s: (x: %dpx, y: %dpx, width: %dpx, height: %dpx),\n",
+				cssString(sprite.Class), sprite.X, sprite.Y, sprite.Width, sprite.Height)
+		}
+		b.WriteString(");\n\n")
+	}
+	fmt.Fprintf(&b, "@mixin %s($name) {\n", base)
+	fmt.Fprintf(&b, "  @if not map.has-key($%s-map, $name) {\n", base)
+	b.WriteString("    @error \"未知的精灵: #{$name}\";\n")
+	b.WriteString("  }\n")
+	fmt.Fprintf(&b, "  $sprite: map.get($%s-map, $name);\n", base)
+	b.WriteString("  display: inline-block;\n")
+	b.WriteString("  overflow: hidden;\n")
+	b.WriteString("  background-repeat: no-repeat;\n")
+	fmt.Fprintf(&b, "  background-image: url($%s-image);\n", base)
+	b.WriteString("  background-position: (-(map.get($sprite, x))) (-(map.get($sprite, y)));\n")
+	b.WriteString("  width: map.get($sprite, width);\n")
+	b.WriteString("  height: map.get($sprite, height);\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// GetLess 生成Less，包含精灵名称到位置和尺寸的map，以及按名称引用精灵的mixin
+// map的键为CSS导出中的类名，使用方式为 .sprite(walk_01);
+func GetLess(sheet Sheet, opts CSSOptions) string {
+	var b strings.Builder
+	base := opts.BaseClass()
+	fmt.Fprintf(&b, "@%s-image: %s;\n\n", base, cssString(styleImage(sheet, opts)))
+	fmt.Fprintf(&b, "@%s-map: {\n", base)
+	for _, sprite := range styleSprites(sheet, opts) {
+		fmt.Fprintf(&b, "  @%s: { x: %dpx; y: %dpx; width: %dpx; height: %dpx; }\n",
+			sprite.Class, sprite.X, sprite.Y, sprite.Width, sprite.Height)
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, ".%s(@name) {\n", base)
+	fmt.Fprintf(&b, "  @x: @%s-map[@@name][x];\n", base)
+	fmt.Fprintf(&b, "  @y: @%s-map[@@name][y];\n", base)
+	b.WriteString("  display: inline-block;\n")
+	b.WriteString("  overflow: hidden;\n")
+	b.WriteString("  background-repeat: no-repeat;\n")
+	fmt.Fprintf(&b, "  background-image: url(@%s-image);\n", base)
+	b.WriteString("  background-position: -@x -@y;\n")
+	fmt.Fprintf(&b, "  width: @%s-map[@@name][width];\n", base)
+	fmt.Fprintf(&b, "  height: @%s-map[@@name][height];\n", base)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// GetTailwindPlugin 生成Tailwind CSS插件，为每个精灵添加一个工具类
+// 类名与CSS导出一致，在tailwind.config.js的plugins中引用即可
+func GetTailwindPlugin(sheet Sheet, opts CSSOptions) string {
+	var b strings.Builder
+	b.WriteString("// 在 tailwind.config.js 的 plugins 中 require 此文件\n")
+	b.WriteString("const plugin = require('tailwindcss/plugin')\n\n")
+	b.WriteString("module.exports = plugin(function ({ addUtilities }) {\n")
+	b.WriteString("  addUtilities({\n")
+	fmt.Fprintf(&b, "    %s: {\n", strconv.Quote("."+opts.BaseClass()))
+	b.WriteString("      display: 'inline-block',\n")
+	b.WriteString("      overflow: 'hidden',\n")
+	b.WriteString("      backgroundRepeat: 'no-repeat',\n")
+	fmt.Fprintf(&b, "      backgroundImage: %s,\n", strconv.Quote("url("+cssString(styleImage(sheet, opts))+")"))
+	b.WriteString("    },\n")
+	for _, sprite := range styleSprites(sheet, opts) {
+		fmt.Fprintf(&b, "    %s: {\n", strconv.Quote("."+sprite.Class))
+		fmt.Fprintf(&b, "      width: '%dpx',\n", sprite.Width)
+		fmt.Fprintf(&b, "      height: '%dpx',\n", sprite.Height)
+		fmt.Fprintf(&b, "      backgroundPosition: '%dpx %dpx',\n", -sprite.X, -sprite.Y)
+		b.WriteString("    },\n")
+	}
+	b.WriteString("  })\n")
+	b.WriteString("})\n")
+	return b.String()
+}
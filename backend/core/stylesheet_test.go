@@ -0,0 +1,88 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tailwindRunner 用桩模块代替tailwindcss/plugin，输出插件添加的工具类
+const tailwindRunner = `
+const Module = require('module')
+const load = Module._load
+Module._load = function (request, ...args) {
+  return request === 'tailwindcss/plugin' ? (fn) => fn : load.call(this, request, ...args)
+}
+let utilities
+require(process.argv[1])({ addUtilities: (u) => { utilities = u } })
+console.log(JSON.stringify(utilities))
+`
+
+func TestGetSCSS(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	scss := GetSCSS(sheet, CSSOptions{Prefix: "hero"})
+	for _, want := range []string{
+		`$hero-image: "sheet.png";`,
+		`"walk_1": (x: 8px, y: 0px, width: 6px, height: 5px),`,
+		"@mixin hero($name) {",
+		"map.has-key($hero-map, $name)",
+	} {
+		if !strings.Contains(scss, want) {
+			t.Errorf("缺少 %q:\n%s", want, scss)
+		}
+	}
+	if empty := GetSCSS(testSheet(8, 8), CSSOptions{}); !strings.Contains(empty, "$sprite-map: ();") {
+		t.Errorf("空图集应输出空map:\n%s", empty)
+	}
+}
+
+func TestGetLess(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	less := GetLess(sheet, CSSOptions{Image: "img/sheet.png"})
+	for _, want := range []string{
+		`@sprite-image: "img/sheet.png";`,
+		"@walk_1: { x: 8px; y: 0px; width: 6px; height: 5px; }",
+		".sprite(@name) {",
+		"@x: @sprite-map[@@name][x];",
+	} {
+		if !strings.Contains(less, want) {
+			t.Errorf("缺少 %q:\n%s", want, less)
+		}
+	}
+}
+
+func TestGetTailwindPlugin(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("未安装node")
+	}
+	sheet := testSheet(32, 16, atlasFrames()...)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sprites.js")
+	if err := os.WriteFile(path, []byte(GetTailwindPlugin(sheet, CSSOptions{Prefix: "hero", Template: "{prefix}-{name}"})), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(node, "-e", tailwindRunner, path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("执行插件失败: %v\n%s", err, out)
+	}
+	var utilities map[string]map[string]string
+	if err := json.Unmarshal(out, &utilities); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if got := utilities[".hero"]["backgroundImage"]; got != `url("sheet.png")` {
+		t.Errorf("backgroundImage = %q", got)
+	}
+	want := map[string]string{"width": "6px", "height": "5px", "backgroundPosition": "-8px 0px"}
+	for key, value := range want {
+		if got := utilities[".hero-walk_1"][key]; got != value {
+			t.Errorf(".hero-walk_1 %s = %q, want %q", key, got, value)
+		}
+	}
+	if len(utilities) != len(sheet.Frames)+1 {
+		t.Errorf("工具类数量 = %d, want %d", len(utilities), len(sheet.Frames)+1)
+	}
+}
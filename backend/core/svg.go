@@ -0,0 +1,165 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SVG轮廓导出格式
+const SVGFormat = "svg"
+
+// svgDir 逐个精灵导出时SVG所在的子目录
+const svgDir = "svg"
+
+// SVGOptions SVG轮廓导出选项
+type SVGOptions struct {
+	Tolerance  float64 `json:"tolerance"`  // 简化轮廓时允许的最大偏差（像素），为0时保留像素边缘
+	Labels     bool    `json:"labels"`     // 在轮廓旁显示精灵名称
+	Background bool    `json:"background"` // 引用图集作为背景图
+	Separate   bool    `json:"separate"`   // 每个精灵输出一个SVG，否则整张图集输出一个SVG
+	Stroke     string  `json:"stroke"`     // 轮廓颜色，默认#ff0000
+}
+
+// Validate 校验SVG导出选项
+func (o SVGOptions) Validate() error {
+	if o.Tolerance < 0 {
+		return errors.New("轮廓简化偏差不能为负数")
+	}
+	if strings.ContainsAny(o.Stroke, `"<>&`) {
+		return fmt.Errorf("无效的轮廓颜色: %s", o.Stroke)
+	}
+	return nil
+}
+
+// withDefaults 补全默认选项
+func (o SVGOptions) withDefaults() SVGOptions {
+	if o.Stroke == "" {
+		o.Stroke = "#ff0000"
+	}
+	return o
+}
+
+// GetSVG 生成marching squares检测出的精灵轮廓SVG
+// 每个精灵一个path，孔洞为同一path中的子路径并使用evenodd填充规则，精灵名称写入title。
+// 整张图集模式下坐标与图集像素一致；逐个精灵模式下每个SVG为精灵的原始尺寸和方向，
+// 输出到svg目录，背景图通过变换引用图集中的对应区域
+func GetSVG(sheet Sheet, baseName string, opts SVGOptions) []ExportFile {
+	opts = opts.withDefaults()
+	if !opts.Separate {
+		return []ExportFile{{Name: baseName + ".svg", Content: sheetSVG(sheet, opts)}}
+	}
+
+	files := make([]ExportFile, 0, len(sheet.Frames))
+	names := frameFilenames(baseName, sheet.Frames)
+	for i, frame := range sheet.Frames {
+		name := strings.TrimSuffix(names[i], ".png") + ".svg"
+		files = append(files, ExportFile{Name: path.Join(svgDir, name), Content: spriteSVG(sheet, i, frame, opts)})
+	}
+	return files
+}
+
+// sheetSVG 整张图集的轮廓，按图集中存放的方向追踪
+func sheetSVG(sheet Sheet, opts SVGOptions) string {
+	var b strings.Builder
+	svgOpen(&b, sheet.Width, sheet.Height)
+	if opts.Background {
+		fmt.Fprintf(&b, "  <image xlink:href=\"%s\" width=\"%d\" height=\"%d\"/>\n", xmlEscape(sheet.Image), sheet.Width, sheet.Height)
+	}
+	for i, frame := range sheet.Frames {
+		rect := frame.Rect
+		var outlines []Outline
+		if sheet.img != nil {
+			region := cropImage(sheet.img, rect.LT.X, rect.LT.Y, rect.RT.X-rect.LT.X, rect.RB.Y-rect.RT.Y)
+			outlines = imageOutlines(region, opts.Tolerance, true)
+		}
+		svgSprite(&b, FrameName(i, frame), outlines, rect.LT.X, rect.LT.Y, opts)
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// spriteSVG 单个精灵的轮廓，还原旋转和透明边
+func spriteSVG(sheet Sheet, index int, frame Frame, opts SVGOptions) string {
+	sourceW, sourceH := frame.SourceSize()
+	var b strings.Builder
+	svgOpen(&b, sourceW, sourceH)
+	if opts.Background {
+		// 图集中存放的区域变换到精灵的原始坐标，裁剪掉相邻的精灵
+		rect := frame.Rect
+		x, y := rect.LT.X, rect.LT.Y
+		w, h := rect.RT.X-rect.LT.X, rect.RB.Y-rect.RT.Y
+		trimW, trimH := w, h
+		if frame.Rotation == 90 || frame.Rotation == 270 {
+			trimW, trimH = h, w
+		}
+		ox, oy := 0, 0
+		if frame.Trimmed {
+			ox, oy = frame.OffsetX, frame.OffsetY
+		}
+		var transform string
+		switch frame.Rotation {
+		case 90:
+			transform = fmt.Sprintf("matrix(0 -1 1 0 %d %d)", ox-y, trimH+x+oy)
+		case 270:
+			transform = fmt.Sprintf("matrix(0 1 -1 0 %d %d)", trimW+y+ox, oy-x)
+		default:
+			transform = fmt.Sprintf("translate(%d %d)", ox-x, oy-y)
+		}
+		fmt.Fprintf(&b, "  <clipPath id=\"frame\"><rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"/></clipPath>\n", ox, oy, trimW, trimH)
+		fmt.Fprintf(&b, "  <g clip-path=\"url(#frame)\"><image xlink:href=\"%s\" width=\"%d\" height=\"%d\" transform=\"%s\"/></g>\n",
+			xmlEscape("../"+sheet.Image), sheet.Width, sheet.Height, transform)
+	}
+	var outlines []Outline
+	if sheet.img != nil {
+		outlines = FrameOutlines(sheet.img, frame, opts.Tolerance)
+	}
+	svgSprite(&b, FrameName(index, frame), outlines, 0, 0, opts)
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// svgOpen 写入svg根元素
+func svgOpen(b *strings.Builder, width, height int) {
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(b, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+}
+
+// svgSprite 写入一个精灵的轮廓，(dx, dy)为轮廓坐标的偏移
+func svgSprite(b *strings.Builder, name string, outlines []Outline, dx, dy int, opts SVGOptions) {
+	fmt.Fprintf(b, "  <g>\n    <title>%s</title>\n", xmlEscape(name))
+	if len(outlines) > 0 {
+		var d strings.Builder
+		for _, outline := range outlines {
+			svgSubpath(&d, outline.Outer, dx, dy)
+			for _, hole := range outline.Holes {
+				svgSubpath(&d, hole, dx, dy)
+			}
+		}
+		fmt.Fprintf(b, "    <path d=\"%s\" fill=\"none\" fill-rule=\"evenodd\" stroke=\"%s\" stroke-width=\"1\" vector-effect=\"non-scaling-stroke\"/>\n",
+			d.String(), opts.Stroke)
+	}
+	if opts.Labels {
+		// 名称写在精灵区域的左上角内侧，避免超出画布
+		fmt.Fprintf(b, "    <text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"8\" dominant-baseline=\"hanging\" fill=\"%s\">%s</text>\n",
+			dx+1, dy+1, opts.Stroke, xmlEscape(name))
+	}
+	b.WriteString("  </g>\n")
+}
+
+// svgSubpath 写入一条闭合子路径
+func svgSubpath(d *strings.Builder, points []Point, dx, dy int) {
+	for i, p := range points {
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+			if d.Len() > 0 {
+				d.WriteByte(' ')
+			}
+		}
+		fmt.Fprintf(d, "%s%d %d", cmd, p.X+dx, p.Y+dy)
+	}
+	d.WriteByte('Z')
+}
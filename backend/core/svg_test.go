@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// svgDoc SVG中测试关心的部分
+type svgDoc struct {
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+	Groups []struct {
+		Title string `xml:"title"`
+		Path  *struct {
+			D        string `xml:"d,attr"`
+			FillRule string `xml:"fill-rule,attr"`
+			Stroke   string `xml:"stroke,attr"`
+		} `xml:"path"`
+		Text string `xml:"text"`
+	} `xml:"g"`
+}
+
+// parseSVG 解析生成的SVG
+func parseSVG(t *testing.T, data string) svgDoc {
+	t.Helper()
+	var doc svgDoc
+	if err := xml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("SVG格式错误: %v\n%s", err, data)
+	}
+	return doc
+}
+
+// ringSheet 包含一个带孔洞的方框和一个实心方块的图集
+func ringSheet() Sheet {
+	img := maskImage(
+		"####.##",
+		"#..#.##",
+		"#..#...",
+		"####...",
+	)
+	return NewSheet("ring.png", img, []Frame{
+		{Name: `ring<&>`, Rect: NewRect(0, 0, 4, 4)},
+		{Name: "dot", Rect: NewRect(5, 0, 2, 2)},
+	})
+}
+
+func TestGetSVGSheet(t *testing.T) {
+	files := GetSVG(ringSheet(), "ring", SVGOptions{Labels: true, Stroke: "blue"})
+	if len(files) != 1 || files[0].Name != "ring.svg" {
+		t.Fatalf("文件 = %v", files)
+	}
+	doc := parseSVG(t, files[0].Content)
+	if doc.Width != 7 || doc.Height != 4 || len(doc.Groups) != 2 {
+		t.Fatalf("SVG = %+v", doc)
+	}
+	ring, dot := doc.Groups[0], doc.Groups[1]
+	if ring.Title != "ring<&>" || ring.Text != "ring<&>" {
+		t.Errorf("名称 = %q %q", ring.Title, ring.Text)
+	}
+	// 孔洞是同一path中的子路径
+	if ring.Path == nil || strings.Count(ring.Path.D, "M") != 2 || ring.Path.FillRule != "evenodd" || ring.Path.Stroke != "blue" {
+		t.Errorf("ring = %+v", ring.Path)
+	}
+	// 整张图集模式下坐标与图集像素一致
+	if dot.Path == nil || !strings.Contains(dot.Path.D, "5 0") || !strings.Contains(dot.Path.D, "7 2") {
+		t.Errorf("dot = %+v", dot.Path)
+	}
+}
+
+func TestGetSVGSeparate(t *testing.T) {
+	files := GetSVG(ringSheet(), "ring", SVGOptions{Separate: true, Background: true})
+	if len(files) != 2 || files[1].Name != "svg/dot.svg" {
+		t.Fatalf("文件 = %v", files)
+	}
+	doc := parseSVG(t, files[1].Content)
+	if doc.Width != 2 || doc.Height != 2 || doc.Groups[1].Path == nil || doc.Groups[1].Path.Stroke != "#ff0000" {
+		t.Errorf("dot.svg = %+v", doc)
+	}
+	if !strings.Contains(files[1].Content, `xlink:href="../ring.png"`) || !strings.Contains(files[1].Content, "translate(-5 0)") {
+		t.Errorf("背景图引用错误:\n%s", files[1].Content)
+	}
+}
+
+func TestSVGOptionsValidate(t *testing.T) {
+	for _, bad := range []SVGOptions{{Tolerance: -1}, {Stroke: `red" onload="x`}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v 应返回错误", bad)
+		}
+	}
+}
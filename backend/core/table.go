@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 表格导出格式
+const (
+	CSVFormat  = "csv"
+	YAMLFormat = "yaml"
+)
+
+// tableColumns 表格导出的列，CSV的表头和YAML的键按此顺序输出
+var tableColumns = []string{"name", "index", "x", "y", "width", "height", "pivot_x", "pivot_y", "group", "pixels"}
+
+// tableRow 表格中的一个精灵
+type tableRow struct {
+	Name          string
+	Index         int
+	X, Y          int
+	Width, Height int
+	Pivot         Pivot
+	Group         string // 所属动画，不属于任何动画时为空
+	Pixels        int    // 不透明像素数
+}
+
+// values 按tableColumns的顺序返回各列的值
+func (r tableRow) values() []string {
+	return []string{
+		r.Name,
+		strconv.Itoa(r.Index),
+		strconv.Itoa(r.X),
+		strconv.Itoa(r.Y),
+		strconv.Itoa(r.Width),
+		strconv.Itoa(r.Height),
+		strconv.FormatFloat(r.Pivot.X, 'f', -1, 64),
+		strconv.FormatFloat(r.Pivot.Y, 'f', -1, 64),
+		r.Group,
+		strconv.Itoa(r.Pixels),
+	}
+}
+
+// tableRows 汇总每个精灵的表格行
+func tableRows(sheet Sheet) []tableRow {
+	groups := make([]string, len(sheet.Frames))
+	for _, animation := range GroupAnimations(sheet.Frames) {
+		for _, index := range animation.Frames {
+			groups[index] = animation.Name
+		}
+	}
+
+	var data []uint8
+	if sheet.img != nil {
+		data = alphaData(sheet.img)
+	}
+
+	rows := make([]tableRow, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		rect := frame.Rect
+		rows[i] = tableRow{
+			Name:   FrameName(i, frame),
+			Index:  i,
+			X:      rect.LT.X,
+			Y:      rect.LT.Y,
+			Width:  rect.RT.X - rect.LT.X,
+			Height: rect.RB.Y - rect.RT.Y,
+			Pivot:  frame.PivotOrDefault(),
+			Group:  groups[i],
+			Pixels: opaquePixels(data, sheet.Width, sheet.Height, rect),
+		}
+	}
+	return rows
+}
+
+// opaquePixels 统计区域内的不透明像素数，data为alphaData返回的像素数据
+func opaquePixels(data []uint8, width, height int, rect Rect) int {
+	if data == nil {
+		return 0
+	}
+	count := 0
+	for y := max(rect.LT.Y, 0); y < min(rect.RB.Y, height); y++ {
+		for x := max(rect.LT.X, 0); x < min(rect.RT.X, width); x++ {
+			if !isAlpha(data, x, y, width, height) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// GetCSV 生成带表头的CSV精灵列表，按RFC 4180转义包含逗号、引号或换行的字段
+func GetCSV(sheet Sheet) string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(tableColumns)
+	for _, row := range tableRows(sheet) {
+		w.Write(row.values())
+	}
+	w.Flush()
+	return b.String()
+}
+
+// GetYAML 生成YAML精灵列表，每个精灵的键与CSV的列相同且顺序一致
+func GetYAML(sheet Sheet) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "image: %s\n", yamlString(sheet.Image))
+	fmt.Fprintf(&b, "width: %d\n", sheet.Width)
+	fmt.Fprintf(&b, "height: %d\n", sheet.Height)
+
+	rows := tableRows(sheet)
+	if len(rows) == 0 {
+		b.WriteString("sprites: []\n")
+		return b.String()
+	}
+	b.WriteString("sprites:\n")
+	for _, row := range rows {
+		for i, value := range row.values() {
+			prefix := "    "
+			if i == 0 {
+				prefix = "  - "
+			}
+			// 名称和动画是字符串，其余列为数字
+			if column := tableColumns[i]; column == "name" || column == "group" {
+				value = yamlString(value)
+			}
+			fmt.Fprintf(&b, "%s%s: %s\n", prefix, tableColumns[i], value)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tableSheet 包含动画和需要转义的名称的图集
+func tableSheet() Sheet {
+	frames := append(atlasFrames(),
+		Frame{Name: `a,"b"`, Rect: NewRect(0, 8, 2, 2)},
+		Frame{Name: "123", Rect: NewRect(4, 8, 2, 2)},
+		Frame{Name: "yes: no", Rect: NewRect(8, 8, 2, 2)})
+	return testSheet(32, 16, frames...)
+}
+
+func TestGetCSV(t *testing.T) {
+	sheet := tableSheet()
+	records, err := csv.NewReader(strings.NewReader(GetCSV(sheet))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(records[0], tableColumns) || len(records) != len(sheet.Frames)+1 {
+		t.Fatalf("表头或行数错误: %v", records)
+	}
+	want := []string{"walk_1.png", "1", "8", "0", "6", "5", "0.5", "0.5", "walk", "30"}
+	if !reflect.DeepEqual(records[2], want) {
+		t.Errorf("walk_1 = %v, want %v", records[2], want)
+	}
+	if records[5][0] != `a,"b"` || records[4][6] != "0.5" || records[4][7] != "1" {
+		t.Errorf("转义或轴心错误: %v %v", records[5], records[4])
+	}
+}
+
+func TestGetYAML(t *testing.T) {
+	sheet := tableSheet()
+	var doc struct {
+		Image         string
+		Width, Height int
+		Sprites       []map[string]any
+	}
+	data := GetYAML(sheet)
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("YAML格式错误: %v\n%s", err, data)
+	}
+	if doc.Image != "sheet.png" || doc.Width != 32 || doc.Height != 16 || len(doc.Sprites) != len(sheet.Frames) {
+		t.Fatalf("YAML = %+v", doc)
+	}
+	// 名称和动画始终解析为字符串，列与CSV一致
+	records, _ := csv.NewReader(strings.NewReader(GetCSV(sheet))).ReadAll()
+	for i, sprite := range doc.Sprites {
+		for j, column := range tableColumns {
+			if got := yamlScalar(sprite[column]); got != records[i+1][j] {
+				t.Errorf("精灵 %d 的 %s = %q, CSV为 %q", i, column, got, records[i+1][j])
+			}
+		}
+	}
+
+	var empty struct{ Sprites []any }
+	if err := yaml.Unmarshal([]byte(GetYAML(testSheet(8, 8))), &empty); err != nil || empty.Sprites == nil {
+		t.Errorf("空图集应输出空列表: %v", err)
+	}
+}
+
+// yamlScalar 将YAML解析出的值格式化为与CSV相同的文本
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	}
+	return "<" + reflect.TypeOf(v).String() + ">"
+}
@@ -0,0 +1,314 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// 用户模板导出格式
+const TemplateFormat = "template"
+
+// TemplateOptions 用户模板导出选项
+type TemplateOptions struct {
+	File   string `json:"file"`   // 模板文件，API中为已上传的文件名，命令行中为文件路径
+	Output string `json:"output"` // 输出文件名，默认为 基础名称+模板文件去除.tmpl后的扩展名
+	Text   string `json:"-"`      // 模板内容，由调用方读取模板文件后填入
+}
+
+// Validate 校验用户模板选项
+func (o TemplateOptions) Validate() error {
+	if o.File == "" {
+		return errors.New("使用模板导出需要提供模板文件")
+	}
+	if o.Output != "" && (path.Base(o.Output) != o.Output || strings.ContainsAny(o.Output, `\:`) || strings.HasPrefix(o.Output, ".")) {
+		return fmt.Errorf("无效的输出文件名: %s", o.Output)
+	}
+	return nil
+}
+
+// filename 返回模板输出的文件名
+func (o TemplateOptions) filename(baseName string) string {
+	if o.Output != "" {
+		return o.Output
+	}
+	name := path.Base(strings.ReplaceAll(o.File, `\`, "/"))
+	for _, ext := range []string{".tmpl", ".tpl", ".gotmpl"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	ext := path.Ext(name)
+	if ext == "" {
+		ext = ".txt"
+	}
+	return baseName + ext
+}
+
+// TemplateModel 模板中通过 . 访问的数据
+type TemplateModel struct {
+	Name      string           // 导出的基础名称，如 sheet
+	Image     string           // 图集图片文件名
+	ImageURL  string           // 样式中引用的图集url，未设置CSS选项时同Image
+	Width     int              // 图集宽度
+	Height    int              // 图集高度
+	BaseClass string           // CSS中所有精灵共用的类名
+	CSS       CSSOptions       // CSS导出选项
+	Sprites   []TemplateSprite // 所有精灵，按图集中的顺序
+	Groups    []TemplateGroup  // 按帧名称末尾的帧号分组的动画
+	Version   int              // 导出JSON的格式版本号，即JSONVersion
+}
+
+// TemplateSprite 模板中的一个精灵
+type TemplateSprite struct {
+	Index        int               // 在图集中的序号
+	Name         string            // 帧名称，未命名的帧为spriteN
+	Class        string            // 按CSS命名规则生成的类名
+	X            int               // 在图集中占用区域的左上角横坐标
+	Y            int               // 在图集中占用区域的左上角纵坐标
+	Width        int               // 在图集中占用区域的宽度
+	Height       int               // 在图集中占用区域的高度
+	Rotation     int               // 在图集中存放时顺时针旋转的角度
+	Rotated      bool              // 是否旋转存放
+	Trimmed      bool              // 是否去除了透明边
+	SourceWidth  int               // 原始宽度
+	SourceHeight int               // 原始高度
+	OffsetX      int               // 去除透明边后的图像在原始尺寸中的位置
+	OffsetY      int               //
+	Pivot        Pivot             // 锚点，取值为相对原始尺寸的比例，未设置时为中心点
+	HasPivot     bool              // 是否设置了锚点
+	Duration     int               // 帧时长（毫秒），未指定时为0
+	Properties   map[string]string // 自定义属性
+	Group        string            // 所属动画名称，不属于任何动画时为空
+	FrameNumber  int               // 帧名称末尾的帧号，没有帧号时为-1
+}
+
+// TemplateGroup 模板中的一组动画
+type TemplateGroup struct {
+	Name   string           // 动画名称
+	Class  string           // 动画的类名，与css-keyframes格式相同，为{prefix}-anim-{name}
+	Frames []TemplateSprite // 按帧号排列的帧
+}
+
+// NewTemplateModel 根据图集创建模板数据
+func NewTemplateModel(sheet Sheet, baseName string, cssOpts CSSOptions) TemplateModel {
+	model := TemplateModel{
+		Name:      baseName,
+		Image:     sheet.Image,
+		ImageURL:  styleImage(sheet, cssOpts),
+		Width:     sheet.Width,
+		Height:    sheet.Height,
+		BaseClass: cssOpts.BaseClass(),
+		CSS:       cssOpts,
+		Sprites:   make([]TemplateSprite, 0, len(sheet.Frames)),
+		Version:   JSONVersion,
+	}
+	for i, frame := range sheet.Frames {
+		sourceW, sourceH := frame.SourceSize()
+		group, number, ok := FrameNumber(frame)
+		if !ok {
+			group = ""
+		}
+		model.Sprites = append(model.Sprites, TemplateSprite{
+			Index:        i,
+			Name:         FrameName(i, frame),
+			Class:        cssOpts.ClassName(i, frame),
+			X:            frame.Rect.LT.X,
+			Y:            frame.Rect.LT.Y,
+			Width:        frame.Rect.RT.X - frame.Rect.LT.X,
+			Height:       frame.Rect.RB.Y - frame.Rect.RT.Y,
+			Rotation:     frame.Rotation,
+			Rotated:      frame.Rotated(),
+			Trimmed:      frame.Trimmed,
+			SourceWidth:  sourceW,
+			SourceHeight: sourceH,
+			OffsetX:      frame.OffsetX,
+			OffsetY:      frame.OffsetY,
+			Pivot:        frame.PivotOrDefault(),
+			HasPivot:     frame.Pivot != nil,
+			Duration:     frame.Duration,
+			Properties:   frame.Properties,
+			Group:        group,
+			FrameNumber:  number,
+		})
+	}
+	for _, animation := range GroupAnimations(sheet.Frames) {
+		group := TemplateGroup{Name: animation.Name, Class: cssOpts.AnimationClass(animation.Name)}
+		for _, index := range animation.Frames {
+			group.Frames = append(group.Frames, model.Sprites[index])
+		}
+		model.Groups = append(model.Groups, group)
+	}
+	return model
+}
+
+// templateFuncs 模板中可用的辅助函数
+//
+//	json v           将值编码为JSON
+//	quote s          带引号和转义的字符串，可用于Go、JavaScript等语言
+//	xml s            转义XML特殊字符
+//	cssString s      带引号的CSS字符串
+//	class s          转换为合法的CSS类名
+//	ident s          转换为导出的Go标识符，如 walk_01 转换为 Walk01
+//	trimExt s        去除图片扩展名
+//	retina s         2倍图集的文件名，如 sheet.png 对应 sheet@2x.png
+//	lower/upper s    转换大小写
+//	replace s old new  替换字符串
+//	join list sep    用分隔符连接字符串列表
+//	add/sub/mul/div a b  整数运算，neg a 取相反数
+//	float f          格式化浮点数
+//	last i list      i是否为列表的最后一个序号，用于处理分隔符
+//	keys m           按字母顺序返回map的键
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"quote":     strconv.Quote,
+	"xml":       xmlEscape,
+	"cssString": cssString,
+	"class":     cssClassName,
+	"ident":     func(s string) string { return goIdent(s, "X") },
+	"trimExt":   trimImageExt,
+	"retina":    RetinaImage,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"replace":   func(s, old, new string) string { return strings.ReplaceAll(s, old, new) },
+	"join":      func(list []string, sep string) string { return strings.Join(list, sep) },
+	"add":       func(a, b int) int { return a + b },
+	"sub":       func(a, b int) int { return a - b },
+	"mul":       func(a, b int) int { return a * b },
+	"div": func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errors.New("除数为0")
+		}
+		return a / b, nil
+	},
+	"neg":   func(a int) int { return -a },
+	"float": formatFloat,
+	"last": func(i int, list any) (bool, error) {
+		switch l := list.(type) {
+		case []TemplateSprite:
+			return i == len(l)-1, nil
+		case []TemplateGroup:
+			return i == len(l)-1, nil
+		case []string:
+			return i == len(l)-1, nil
+		case int:
+			return i == l-1, nil
+		}
+		return false, fmt.Errorf("last不支持的类型: %T", list)
+	},
+	"keys": func(m map[string]string) []string {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+}
+
+// TemplateError 模板解析或执行错误，包含出错的行号
+type TemplateError struct {
+	Name string // 模板名称
+	Line int    // 出错的行号，无法确定时为0
+	Err  error
+}
+
+// Error 返回带行号的错误信息
+func (e *TemplateError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("模板 %s 第 %d 行: %v", e.Name, e.Line, e.Err)
+	}
+	return fmt.Sprintf("模板 %s: %v", e.Name, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// templateLine 匹配text/template错误信息中的模板名称和行号
+var templateLine = regexp.MustCompile(`template: [^:]*:(\d+)`)
+
+// templateError 将text/template的错误转换为TemplateError
+func templateError(name string, err error) error {
+	e := &TemplateError{Name: name, Err: err}
+	if m := templateLine.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+// ParseTemplate 解析用户模板，错误为带行号的*TemplateError
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, templateError(name, err)
+	}
+	return tmpl, nil
+}
+
+// ExecuteTemplate 使用模板数据执行模板，错误为带行号的*TemplateError
+func ExecuteTemplate(tmpl *template.Template, model TemplateModel) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, model); err != nil {
+		return "", templateError(tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// GetTemplate 使用用户模板导出图集
+func GetTemplate(sheet Sheet, baseName string, opts TemplateOptions, cssOpts CSSOptions) (ExportFile, error) {
+	if err := opts.Validate(); err != nil {
+		return ExportFile{}, err
+	}
+	name := path.Base(strings.ReplaceAll(opts.File, `\`, "/"))
+	tmpl, err := ParseTemplate(name, opts.Text)
+	if err != nil {
+		return ExportFile{}, err
+	}
+	content, err := ExecuteTemplate(tmpl, NewTemplateModel(sheet, baseName, cssOpts))
+	if err != nil {
+		return ExportFile{}, err
+	}
+	return ExportFile{Name: opts.filename(baseName), Content: content}, nil
+}
+
+// 内置导出格式的模板
+var (
+	//go:embed templates/css.tmpl
+	cssTemplateText string
+	//go:embed templates/json.tmpl
+	jsonTemplateText string
+	//go:embed templates/json-legacy.tmpl
+	legacyJSONTemplateText string
+
+	cssTemplate        = mustParseTemplate("css.tmpl", cssTemplateText)
+	jsonTemplate       = mustParseTemplate("json.tmpl", jsonTemplateText)
+	legacyJSONTemplate = mustParseTemplate("json-legacy.tmpl", legacyJSONTemplateText)
+)
+
+// mustParseTemplate 解析内置模板，失败时panic
+func mustParseTemplate(name, text string) *template.Template {
+	tmpl, err := ParseTemplate(name, text)
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
+}
+
+// executeBuiltin 执行内置模板，内置模板只使用模型中存在的字段，执行不会失败
+func executeBuiltin(tmpl *template.Template, model TemplateModel) string {
+	content, err := ExecuteTemplate(tmpl, model)
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
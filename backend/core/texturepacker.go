@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// TexturePacker JSON 导出格式
+const (
+	TexturePackerHash  = "texturepacker-hash"
+	TexturePackerArray = "texturepacker-array"
+)
+
+// tpSize TexturePacker JSON中的尺寸
+type tpSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// tpExportFrame TexturePacker JSON中导出的帧
+type tpExportFrame struct {
+	Filename         string `json:"filename,omitempty"`
+	Frame            tpRect `json:"frame"`
+	Rotated          bool   `json:"rotated"`
+	Trimmed          bool   `json:"trimmed"`
+	SpriteSourceSize tpRect `json:"spriteSourceSize"`
+	SourceSize       tpSize `json:"sourceSize"`
+	Pivot            Pivot  `json:"pivot"`
+}
+
+// tpMeta TexturePacker JSON中的meta
+type tpMeta struct {
+	App     string `json:"app"`
+	Version string `json:"version"`
+	Image   string `json:"image"`
+	Format  string `json:"format"`
+	Size    tpSize `json:"size"`
+	Scale   string `json:"scale"`
+}
+
+// tpHashFrames 按帧顺序输出的hash格式frames
+type tpHashFrames []tpExportFrame
+
+// MarshalJSON 将帧编码为以文件名为键的对象，保持帧的顺序
+func (frames tpHashFrames) MarshalJSON() ([]byte, error) {
+	keys := make([]string, len(frames))
+	values := make([]any, len(frames))
+	for i, frame := range frames {
+		keys[i] = frame.Filename
+		frame.Filename = ""
+		values[i] = frame
+	}
+	return marshalOrderedObject(keys, values)
+}
+
+// marshalOrderedObject 按给定顺序将键值编码为JSON对象
+func marshalOrderedObject(keys []string, values []any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// GetTexturePackerJson 生成TexturePacker兼容的JSON，可被Phaser和PixiJS加载
+// array为true时输出JSON Array格式，否则输出JSON Hash格式，重复的帧名称加上序号。
+// TexturePacker格式只能表示顺时针旋转存放的帧，有逆时针旋转存放的帧时返回错误
+func GetTexturePackerJson(sheet Sheet, array bool) (string, error) {
+	if err := checkClockwise(sheet.Frames); err != nil {
+		return "", err
+	}
+	names := frameNames(sheet.Frames)
+	frames := make([]tpExportFrame, 0, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		sourceW, sourceH := frame.SourceSize()
+		w, h := frame.Width(), frame.Height()
+		frames = append(frames, tpExportFrame{
+			Filename: names[i],
+			// frame的宽高为旋转前的尺寸
+			Frame:            tpRect{X: frame.Rect.LT.X, Y: frame.Rect.LT.Y, W: w, H: h},
+			Rotated:          frame.Rotated(),
+			Trimmed:          frame.Trimmed,
+			SpriteSourceSize: tpRect{X: frame.OffsetX, Y: frame.OffsetY, W: w, H: h},
+			SourceSize:       tpSize{W: sourceW, H: sourceH},
+			Pivot:            frame.PivotOrDefault(),
+		})
+	}
+
+	meta := tpMeta{
+		App:     "sprite-cuter",
+		Version: "1.0",
+		Image:   sheet.Image,
+		Format:  "RGBA8888",
+		Size:    tpSize{W: sheet.Width, H: sheet.Height},
+		Scale:   "1",
+	}
+
+	var v any
+	if array {
+		v = struct {
+			Frames []tpExportFrame `json:"frames"`
+			Meta   tpMeta          `json:"meta"`
+		}{frames, meta}
+	} else {
+		v = struct {
+			Frames tpHashFrames `json:"frames"`
+			Meta   tpMeta       `json:"meta"`
+		}{frames, meta}
+	}
+
+	// 结构中只有字符串、数值和布尔值，编码不会失败
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data), nil
+}
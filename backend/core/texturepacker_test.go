@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// atlasFrames 导出格式往返测试使用的帧：普通、去除透明边、顺时针旋转和带锚点的帧
+func atlasFrames() []Frame {
+	return []Frame{
+		{Name: "idle.png", Rect: NewRect(0, 0, 8, 8), SourceW: 8, SourceH: 8},
+		{Name: "walk_1.png", Rect: NewRect(8, 0, 6, 5), Trimmed: true, SourceW: 10, SourceH: 9, OffsetX: 3, OffsetY: 1},
+		{Name: "walk_2.png", Rect: NewRect(16, 0, 5, 7), Rotation: 90, Trimmed: true, SourceW: 8, SourceH: 8, OffsetX: 1, OffsetY: 2},
+		{Name: "jump.png", Rect: NewRect(24, 0, 4, 6), SourceW: 4, SourceH: 6, Pivot: &Pivot{X: 0.5, Y: 1}},
+	}
+}
+
+// assertFrames 比较解析回来的帧与导出的帧，want中的帧名称都应出现在got中
+func assertFrames(t *testing.T, got, want []Frame) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("解析出 %d 个帧, want %d", len(got), len(want))
+	}
+	byName := map[string]Frame{}
+	for _, frame := range got {
+		byName[frame.Name] = frame
+	}
+	for _, w := range want {
+		g, ok := byName[w.Name]
+		if !ok {
+			t.Errorf("缺少帧 %s", w.Name)
+			continue
+		}
+		if g.Rect != w.Rect || g.Rotation != w.Rotation || g.Trimmed != w.Trimmed {
+			t.Errorf("帧 %s: 区域 %v 旋转 %d 去边 %v, want %v %d %v", w.Name, g.Rect, g.Rotation, g.Trimmed, w.Rect, w.Rotation, w.Trimmed)
+		}
+		gw, gh := g.SourceSize()
+		ww, wh := w.SourceSize()
+		if gw != ww || gh != wh || g.OffsetX != w.OffsetX || g.OffsetY != w.OffsetY {
+			t.Errorf("帧 %s: 原始尺寸 %dx%d 偏移 %d,%d, want %dx%d %d,%d", w.Name, gw, gh, g.OffsetX, g.OffsetY, ww, wh, w.OffsetX, w.OffsetY)
+		}
+	}
+}
+
+// rotatedCCW 返回包含逆时针旋转存放的帧的图集
+func rotatedCCW() Sheet {
+	return testSheet(16, 16, Frame{Name: "a", Rect: NewRect(0, 0, 4, 8), Rotation: 270, SourceW: 8, SourceH: 4})
+}
+
+func TestTexturePackerRoundTrip(t *testing.T) {
+	sheet := testSheet(32, 8, atlasFrames()...)
+	for _, array := range []bool{false, true} {
+		data, err := GetTexturePackerJson(sheet, array)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames, err := ParseAtlas("sheet.json", []byte(data))
+		if err != nil {
+			t.Fatalf("array=%v: %v", array, err)
+		}
+		assertFrames(t, frames, atlasFrames())
+		// 两种格式都保持帧的顺序
+		for i, frame := range frames {
+			if frame.Name != atlasFrames()[i].Name {
+				t.Errorf("array=%v: 第%d帧为%s", array, i, frame.Name)
+			}
+		}
+		if frames[3].Pivot == nil || *frames[3].Pivot != (Pivot{X: 0.5, Y: 1}) {
+			t.Errorf("array=%v: 锚点 = %v", array, frames[3].Pivot)
+		}
+	}
+}
+
+func TestTexturePackerDuplicateNames(t *testing.T) {
+	sheet := testSheet(16, 8,
+		Frame{Name: "a.png", Rect: NewRect(0, 0, 4, 4)},
+		Frame{Name: "a.png", Rect: NewRect(8, 0, 4, 4)},
+	)
+	data, err := GetTexturePackerJson(sheet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := ParseAtlas("sheet.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Name != "a.png" || frames[1].Name != "a_2.png" || frames[1].Rect != NewRect(8, 0, 4, 4) {
+		t.Errorf("重复名称的帧 = %+v", frames)
+	}
+}
+
+func TestClockwiseOnlyFormatsRejectCCW(t *testing.T) {
+	sheet := rotatedCCW()
+	exports := map[string]func(Sheet) (string, error){
+		"hash":     func(s Sheet) (string, error) { return GetTexturePackerJson(s, false) },
+		"array":    func(s Sheet) (string, error) { return GetTexturePackerJson(s, true) },
+		"plist":    GetCocos2dPlist,
+		"starling": GetStarlingXML,
+	}
+	for name, export := range exports {
+		_, err := export(sheet)
+		if !errors.Is(err, ErrUnsupportedFrame) {
+			t.Errorf("%s: err = %v, want ErrUnsupportedFrame", name, err)
+		} else if !strings.Contains(err.Error(), "a") {
+			t.Errorf("%s: 错误中应包含帧名称: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tiled 图块集导出格式
+const TiledTSX = "tiled-tsx"
+
+// TiledOptions Tiled 图块集导出选项
+type TiledOptions struct {
+	Collision *bool   `json:"collision"` // 是否按轮廓生成碰撞多边形，默认生成
+	Tolerance float64 `json:"tolerance"` // 简化轮廓时允许的最大偏差（像素），默认1
+}
+
+// withDefaults 补全默认选项
+func (o TiledOptions) withDefaults() TiledOptions {
+	if o.Collision == nil {
+		collision := true
+		o.Collision = &collision
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = 1
+	}
+	return o
+}
+
+// GetTiledTSX 生成Tiled的TSX图块集
+// 按网格切割的图集生成基于整张图集的图块集，图块ID为网格中的位置；
+// 其他图集生成图像集合图块集，每个图块引用导出的单帧图片。
+// 帧名称、所属动画和自定义属性写入图块属性，按名称分组的动画写入首帧的animation，
+// 不透明区域的外轮廓写入图块的碰撞多边形
+func GetTiledTSX(sheet Sheet, baseName string, opts TiledOptions) string {
+	opts = opts.withDefaults()
+	var b strings.Builder
+	line := func(indent int, format string, args ...any) {
+		b.WriteString(strings.Repeat(" ", indent))
+		fmt.Fprintf(&b, format, args...)
+		b.WriteByte('\n')
+	}
+
+	// 计算每个帧对应的图块ID
+	ids := make([]int, len(sheet.Frames))
+	grid := sheet.Grid
+	var columns, tileCount, tileW, tileH, spacing, margin int
+	if grid != nil {
+		tileW, tileH = grid.Width, grid.Height
+		spacing, margin = grid.Spacing, grid.Margin
+		columns = gridCount(sheet.Width, grid.Width, grid.Margin, grid.Spacing)
+		tileCount = columns * gridCount(sheet.Height, grid.Height, grid.Margin, grid.Spacing)
+		for i, frame := range sheet.Frames {
+			col := (frame.Rect.LT.X - grid.Margin) / (grid.Width + grid.Spacing)
+			row := (frame.Rect.LT.Y - grid.Margin) / (grid.Height + grid.Spacing)
+			ids[i] = row*columns + col
+		}
+	} else {
+		tileCount = len(sheet.Frames)
+		for i, frame := range sheet.Frames {
+			ids[i] = i
+			w, h := frame.SourceSize()
+			tileW, tileH = max(tileW, w), max(tileH, h)
+		}
+	}
+
+	// 动画的首帧记录整组动画
+	animations := map[int]Animation{}
+	animationOf := map[int]string{}
+	for _, animation := range GroupAnimations(sheet.Frames) {
+		animations[animation.Frames[0]] = animation
+		for _, index := range animation.Frames {
+			animationOf[index] = animation.Name
+		}
+	}
+
+	line(0, `<?xml version="1.0" encoding="UTF-8"?>`)
+	line(0, `<tileset version="1.10" tiledversion="1.10.2" name="%s" tilewidth="%d" tileheight="%d" spacing="%d" margin="%d" tilecount="%d" columns="%d">`,
+		xmlEscape(baseName), tileW, tileH, spacing, margin, tileCount, columns)
+	if grid != nil {
+		line(1, `<image source="%s" width="%d" height="%d"/>`, xmlEscape(sheet.Image), sheet.Width, sheet.Height)
+	} else {
+		line(1, `<grid orientation="orthogonal" width="1" height="1"/>`)
+	}
+
+	imageNames := frameFilenames(baseName, sheet.Frames)
+	for i, frame := range sheet.Frames {
+		line(1, `<tile id="%d">`, ids[i])
+
+		// 自定义属性
+		properties := map[string]string{"name": trimImageExt(FrameName(i, frame))}
+		if name, ok := animationOf[i]; ok {
+			properties["animation"] = name
+		}
+		for key, value := range frame.Properties {
+			properties[key] = value
+		}
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		line(2, `<properties>`)
+		for _, key := range keys {
+			line(3, `<property name="%s" value="%s"/>`, xmlEscape(key), xmlEscape(properties[key]))
+		}
+		line(2, `</properties>`)
+
+		if grid == nil {
+			w, h := frame.SourceSize()
+			line(2, `<image source="%s" width="%d" height="%d"/>`, xmlEscape(imageNames[i]), w, h)
+		}
+
+		// 碰撞多边形
+		if *opts.Collision && sheet.img != nil {
+			contours := FrameContours(sheet.img, frame, opts.Tolerance)
+			if len(contours) > 0 {
+				line(2, `<objectgroup draworder="index" id="2">`)
+				for n, contour := range contours {
+					points := make([]string, len(contour))
+					for k, p := range contour {
+						points[k] = strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+					}
+					line(3, `<object id="%d" x="0" y="0">`, n+1)
+					line(4, `<polygon points="%s"/>`, strings.Join(points, " "))
+					line(3, `</object>`)
+				}
+				line(2, `</objectgroup>`)
+			}
+		}
+
+		// 动画
+		if animation, ok := animations[i]; ok && len(animation.Frames) > 1 {
+			line(2, `<animation>`)
+			for _, index := range animation.Frames {
+				duration := sheet.Frames[index].Duration
+				if duration <= 0 {
+					duration = 100
+				}
+				line(3, `<frame tileid="%d" duration="%d"/>`, ids[index], duration)
+			}
+			line(2, `</animation>`)
+		}
+		line(1, `</tile>`)
+	}
+	line(0, `</tileset>`)
+	return b.String()
+}
+
+// gridCount 返回图集一个方向上完整单元格的数量
+func gridCount(size, cell, margin, spacing int) int {
+	if size-margin < cell {
+		return 0
+	}
+	return (size-margin-cell)/(cell+spacing) + 1
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// tsxTileset TSX中测试关心的部分
+type tsxTileset struct {
+	TileCount int `xml:"tilecount,attr"`
+	Columns   int `xml:"columns,attr"`
+	Image     *struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+	Tiles []struct {
+		ID         int `xml:"id,attr"`
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"properties>property"`
+		Image *struct {
+			Source string `xml:"source,attr"`
+		} `xml:"image"`
+		Polygons []struct {
+			Points string `xml:"points,attr"`
+		} `xml:"objectgroup>object>polygon"`
+		Animation []struct {
+			TileID   int `xml:"tileid,attr"`
+			Duration int `xml:"duration,attr"`
+		} `xml:"animation>frame"`
+	} `xml:"tile"`
+}
+
+// parseTSX 解析生成的TSX
+func parseTSX(t *testing.T, data string) tsxTileset {
+	t.Helper()
+	var tileset tsxTileset
+	if err := xml.Unmarshal([]byte(data), &tileset); err != nil {
+		t.Fatalf("TSX格式错误: %v\n%s", err, data)
+	}
+	return tileset
+}
+
+func TestTiledTSXGrid(t *testing.T) {
+	// 3x2的网格，只有第1和第5个单元格不透明
+	grid := &GridOptions{Width: 4, Height: 4, Spacing: 1}
+	img := image.NewNRGBA(image.Rect(0, 0, 14, 9))
+	for _, p := range []image.Point{{5, 0}, {6, 6}} {
+		for y := p.Y; y < p.Y+2; y++ {
+			for x := p.X; x < p.X+2; x++ {
+				img.Set(x, y, color.NRGBA{A: 255})
+			}
+		}
+	}
+	frames := FramesFromRects(GetGridSprites(img, *grid))
+	frames[0].Name, frames[1].Name = "coin_1", "coin_2"
+	frames[0].Properties = map[string]string{"type": "pickup"}
+	sheet := NewSheet("tiles.png", img, frames)
+	sheet.Grid = grid
+
+	tileset := parseTSX(t, GetTiledTSX(sheet, "tiles", TiledOptions{}))
+	if tileset.TileCount != 6 || tileset.Columns != 3 || tileset.Image == nil || tileset.Image.Source != "tiles.png" {
+		t.Errorf("图块集属性错误: %+v", tileset)
+	}
+	if len(tileset.Tiles) != 2 || tileset.Tiles[0].ID != 1 || tileset.Tiles[1].ID != 4 {
+		t.Fatalf("图块ID应为网格中的位置: %+v", tileset.Tiles)
+	}
+	first := tileset.Tiles[0]
+	props := map[string]string{}
+	for _, p := range first.Properties {
+		props[p.Name] = p.Value
+	}
+	if props["name"] != "coin_1" || props["animation"] != "coin" || props["type"] != "pickup" {
+		t.Errorf("图块属性 = %v", props)
+	}
+	if len(first.Animation) != 2 || first.Animation[1].TileID != 4 || first.Animation[1].Duration != 100 {
+		t.Errorf("动画 = %+v", first.Animation)
+	}
+	// 碰撞多边形的坐标相对图块左上角
+	if len(first.Polygons) != 1 || !strings.Contains(first.Polygons[0].Points, "0,0") {
+		t.Errorf("碰撞多边形 = %+v", first.Polygons)
+	}
+}
+
+func TestTiledTSXCollection(t *testing.T) {
+	sheet := testSheet(32, 16, atlasFrames()...)
+	collision := false
+	data := GetTiledTSX(sheet, "hero", TiledOptions{Collision: &collision})
+	tileset := parseTSX(t, data)
+	if tileset.Image != nil || tileset.TileCount != len(sheet.Frames) {
+		t.Errorf("图像集合图块集不应引用整张图集: %+v", tileset)
+	}
+	names := frameFilenames("hero", sheet.Frames)
+	for i, tile := range tileset.Tiles {
+		want := names[i]
+		if tile.ID != i || tile.Image == nil || tile.Image.Source != want {
+			t.Errorf("图块 %d 引用 %+v, want %s", i, tile.Image, want)
+		}
+	}
+	if strings.Contains(data, "objectgroup") {
+		t.Error("关闭collision后不应生成碰撞多边形")
+	}
+}
+
+func TestTiledTSXEscapes(t *testing.T) {
+	sheet := testSheet(8, 8, Frame{Name: `a"<b>&`, Rect: NewRect(0, 0, 4, 4)})
+	parseTSX(t, GetTiledTSX(sheet, `x"y`, TiledOptions{}))
+}
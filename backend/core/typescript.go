@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TypeScript 模块导出格式
+const TypeScript = "typescript"
+
+// tsFrame ES模块中的帧
+type tsFrame struct {
+	X            int   `json:"x"`
+	Y            int   `json:"y"`
+	Width        int   `json:"width"`
+	Height       int   `json:"height"`
+	Rotated      bool  `json:"rotated"`
+	Trimmed      bool  `json:"trimmed"`
+	SourceWidth  int   `json:"sourceWidth"`
+	SourceHeight int   `json:"sourceHeight"`
+	OffsetX      int   `json:"offsetX"`
+	OffsetY      int   `json:"offsetY"`
+	Pivot        Pivot `json:"pivot"`
+}
+
+// GetTypeScript 生成ES模块和对应的.d.ts类型声明
+// 帧以去除图片扩展名的名称为键，类型声明中的SpriteName和AnimationName为所有名称的字面量联合类型，
+// 引用不存在的精灵或动画时TypeScript会在编译时报错
+func GetTypeScript(sheet Sheet) (module, declaration string, err error) {
+	var names, keys []string
+	var frames []any
+	seen := map[string]bool{}
+	for i, frame := range sheet.Frames {
+		name := trimImageExt(FrameName(i, frame))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		sourceW, sourceH := frame.SourceSize()
+		names = append(names, name)
+		keys = append(keys, name)
+		frames = append(frames, tsFrame{
+			X:            frame.Rect.LT.X,
+			Y:            frame.Rect.LT.Y,
+			Width:        frame.Rect.RT.X - frame.Rect.LT.X,
+			Height:       frame.Rect.RB.Y - frame.Rect.RT.Y,
+			Rotated:      frame.Rotated(),
+			Trimmed:      frame.Trimmed,
+			SourceWidth:  sourceW,
+			SourceHeight: sourceH,
+			OffsetX:      frame.OffsetX,
+			OffsetY:      frame.OffsetY,
+			Pivot:        frame.PivotOrDefault(),
+		})
+	}
+
+	var animationNames []string
+	var animations []any
+	for _, animation := range GroupAnimations(sheet.Frames) {
+		sequence := make([]string, 0, len(animation.Frames))
+		for _, index := range animation.Frames {
+			sequence = append(sequence, trimImageExt(FrameName(index, sheet.Frames[index])))
+		}
+		animationNames = append(animationNames, animation.Name)
+		animations = append(animations, sequence)
+	}
+
+	framesJSON, err := indentedObject(keys, frames)
+	if err != nil {
+		return "", "", err
+	}
+	animationsJSON, err := indentedObject(animationNames, animations)
+	if err != nil {
+		return "", "", err
+	}
+	image, _ := json.Marshal(sheet.Image)
+
+	var m strings.Builder
+	m.WriteString("// 由 sprite-cuter 生成，请勿手动修改\n\n")
+	fmt.Fprintf(&m, "export const image = %s;\n\n", image)
+	fmt.Fprintf(&m, "export const size = { width: %d, height: %d };\n\n", sheet.Width, sheet.Height)
+	fmt.Fprintf(&m, "export const frames = %s;\n\n", framesJSON)
+	fmt.Fprintf(&m, "export const animations = %s;\n", animationsJSON)
+
+	var d strings.Builder
+	d.WriteString("// 由 sprite-cuter 生成，请勿手动修改\n\n")
+	fmt.Fprintf(&d, "export type SpriteName = %s;\n\n", tsUnion(names))
+	fmt.Fprintf(&d, "export type AnimationName = %s;\n\n", tsUnion(animationNames))
+	d.WriteString("export interface SpriteFrame {\n")
+	d.WriteString("  readonly x: number;\n")
+	d.WriteString("  readonly y: number;\n")
+	d.WriteString("  readonly width: number;\n")
+	d.WriteString("  readonly height: number;\n")
+	d.WriteString("  readonly rotated: boolean;\n")
+	d.WriteString("  readonly trimmed: boolean;\n")
+	d.WriteString("  readonly sourceWidth: number;\n")
+	d.WriteString("  readonly sourceHeight: number;\n")
+	d.WriteString("  readonly offsetX: number;\n")
+	d.WriteString("  readonly offsetY: number;\n")
+	d.WriteString("  readonly pivot: { readonly x: number; readonly y: number };\n")
+	d.WriteString("}\n\n")
+	d.WriteString("export declare const image: string;\n")
+	d.WriteString("export declare const size: { readonly width: number; readonly height: number };\n")
+	d.WriteString("export declare const frames: { readonly [K in SpriteName]: SpriteFrame };\n")
+	d.WriteString("export declare const animations: { readonly [K in AnimationName]: readonly SpriteName[] };\n")
+	return m.String(), d.String(), nil
+}
+
+// indentedObject 按给定顺序将键值编码为缩进的JSON对象
+func indentedObject(keys []string, values []any) (string, error) {
+	data, err := marshalOrderedObject(keys, values)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// tsUnion 返回字符串字面量的联合类型，没有名称时为never
+func tsUnion(names []string) string {
+	if len(names) == 0 {
+		return "never"
+	}
+	literals := make([]string, len(names))
+	for i, name := range names {
+		data, _ := json.Marshal(name)
+		literals[i] = string(data)
+	}
+	if len(literals) == 1 {
+		return literals[0]
+	}
+	return "\n  | " + strings.Join(literals, "\n  | ")
+}
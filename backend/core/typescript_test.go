@@ -0,0 +1,113 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tsSheet 包含动画、重名帧和需要转义的名称的图集
+func tsSheet() Sheet {
+	frames := append(atlasFrames(),
+		Frame{Name: "idle.png", Rect: NewRect(0, 8, 2, 2)},
+		Frame{Name: `say "hi"`, Rect: NewRect(4, 8, 2, 2)})
+	return testSheet(32, 16, frames...)
+}
+
+// writeTypeScript 将模块和类型声明写入临时目录，返回目录
+func writeTypeScript(t *testing.T, sheet Sheet) string {
+	t.Helper()
+	module, declaration, err := GetTypeScript(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writeExport(t, []ExportFile{
+		{Name: "sprites.js", Content: module},
+		{Name: "sprites.d.ts", Content: declaration},
+	})
+}
+
+func TestTypeScriptModuleRuns(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("未安装node")
+	}
+	dir := writeTypeScript(t, tsSheet())
+	// .js按ES模块加载
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"type": "module"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := `import(require('url').pathToFileURL(process.argv[1])).then((m) => console.log(JSON.stringify(m)))`
+	out, err := exec.Command(node, "-e", script, filepath.Join(dir, "sprites.js")).CombinedOutput()
+	if err != nil {
+		t.Fatalf("加载模块失败: %v\n%s", err, out)
+	}
+	var m struct {
+		Image      string
+		Size       struct{ Width, Height int }
+		Frames     map[string]tsFrame
+		Animations map[string][]string
+	}
+	if err := json.Unmarshal(out, &m); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if m.Image != "sheet.png" || m.Size.Width != 32 || m.Size.Height != 16 {
+		t.Errorf("image = %q, size = %+v", m.Image, m.Size)
+	}
+	// 重名的帧只保留第一个
+	if len(m.Frames) != 5 || m.Frames["idle"].Width != 8 {
+		t.Errorf("frames = %+v", m.Frames)
+	}
+	walk := m.Frames["walk_2"]
+	if !walk.Rotated || walk.Width != 5 || walk.SourceWidth != 8 || walk.OffsetX != 1 {
+		t.Errorf("walk_2 = %+v", walk)
+	}
+	if strings.Join(m.Animations["walk"], ",") != "walk_1,walk_2" {
+		t.Errorf("animations = %+v", m.Animations)
+	}
+}
+
+func TestTypeScriptCompiles(t *testing.T) {
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		t.Skip("未安装tsc")
+	}
+	dir := writeTypeScript(t, tsSheet())
+	compile := func(source string) ([]byte, error) {
+		path := filepath.Join(dir, "main.ts")
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return exec.Command(tsc, "--noEmit", "--strict", "--module", "es2020", "--target", "es2020", path).CombinedOutput()
+	}
+
+	valid := `import { frames, animations, image, SpriteName, AnimationName } from "./sprites";
+const name: SpriteName = "walk_1";
+const anim: AnimationName = "walk";
+const width: number = frames[name].width + frames['say "hi"'].height;
+const first: SpriteName = animations[anim][0];
+console.log(image, width, first);
+`
+	if out, err := compile(valid); err != nil {
+		t.Fatalf("编译失败: %v\n%s", err, out)
+	}
+	// 引用不存在的精灵时编译报错
+	if out, err := compile(`import { frames } from "./sprites";
+console.log(frames.missing);
+`); err == nil {
+		t.Errorf("引用不存在的精灵应编译失败:\n%s", out)
+	}
+}
+
+func TestTypeScriptEmptySheet(t *testing.T) {
+	module, declaration, err := GetTypeScript(testSheet(8, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(module, "export const frames = {};") || !strings.Contains(declaration, "export type SpriteName = never;") {
+		t.Errorf("空图集:\n%s\n%s", module, declaration)
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Unity 精灵图导入设置导出格式
+const UnityMeta = "unity-meta"
+
+// unityGUID 根据名称生成稳定的GUID，重复导出时保持不变
+func unityGUID(parts ...string) string {
+	sum := md5.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// unityInternalID 根据名称生成稳定的internalID
+func unityInternalID(sheetName, spriteName string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(sheetName))
+	h.Write([]byte{0})
+	h.Write([]byte(spriteName))
+	id := int64(h.Sum64() >> 1)
+	// 0保留给纹理本身
+	if id == 0 {
+		id = 1
+	}
+	return id
+}
+
+// GetUnityMeta 生成Unity纹理的.meta文件，spriteMode为Multiple，
+// 导入后无需在Sprite Editor中重新切割。
+// rect使用Unity的左下角原点，GUID和internalID由图集的原始名称name和帧名称生成，重复导出时保持不变，
+// 重复的帧名称加上序号区分。Unity不支持旋转存放的帧，这类帧按在图集中占用的区域导出
+func GetUnityMeta(sheet Sheet, name string) string {
+	var b strings.Builder
+	line := func(indent int, format string, args ...any) {
+		b.WriteString(strings.Repeat("  ", indent))
+		fmt.Fprintf(&b, format, args...)
+		b.WriteByte('\n')
+	}
+
+	line(0, "fileFormatVersion: 2")
+	line(0, "guid: %s", unityGUID(name))
+	line(0, "TextureImporter:")
+	line(1, "internalIDToNameTable: []")
+	line(1, "externalObjects: {}")
+	line(1, "serializedVersion: 12")
+	line(1, "mipmaps:")
+	line(2, "enableMipMap: 0")
+	line(1, "isReadable: 0")
+	line(1, "textureType: 8")
+	line(1, "textureShape: 1")
+	line(1, "spriteMode: 2")
+	line(1, "spriteExtrude: 1")
+	line(1, "spriteMeshType: 1")
+	line(1, "alignment: 0")
+	line(1, "spritePivot: {x: 0.5, y: 0.5}")
+	line(1, "spritePixelsToUnits: 100")
+	line(1, "spriteBorder: {x: 0, y: 0, z: 0, w: 0}")
+	line(1, "alphaUsage: 1")
+	line(1, "alphaIsTransparency: 1")
+	line(1, "textureFormat: 1")
+	line(1, "maxTextureSize: 8192")
+	line(1, "textureSettings:")
+	line(2, "serializedVersion: 2")
+	line(2, "filterMode: 0")
+	line(2, "wrapU: 1")
+	line(2, "wrapV: 1")
+	line(1, "spriteSheet:")
+	line(2, "serializedVersion: 2")
+
+	if len(sheet.Frames) == 0 {
+		line(2, "sprites: []")
+	} else {
+		line(2, "sprites:")
+	}
+	names := make([]string, len(sheet.Frames))
+	for i, frame := range sheet.Frames {
+		names[i] = trimImageExt(FrameName(i, frame))
+	}
+	names = uniqueNames(names)
+	for i, frame := range sheet.Frames {
+		x, y := frame.Rect.LT.X, frame.Rect.LT.Y
+		width, height := frame.Rect.RT.X-frame.Rect.LT.X, frame.Rect.RB.Y-frame.Rect.RT.Y
+
+		// 锚点换算到去除透明边后的区域，y轴向上
+		pivot := frame.PivotOrDefault()
+		sourceW, sourceH := frame.SourceSize()
+		pivotX, pivotY := pivot.X, 1-pivot.Y
+		if width > 0 && height > 0 {
+			pivotX = (pivot.X*float64(sourceW) - float64(frame.OffsetX)) / float64(width)
+			pivotY = 1 - (pivot.Y*float64(sourceH)-float64(frame.OffsetY))/float64(height)
+		}
+		alignment := 9 // Custom
+		if pivotX == 0.5 && pivotY == 0.5 {
+			alignment = 0 // Center
+		}
+
+		line(2, "- serializedVersion: 2")
+		line(3, "name: %s", yamlString(names[i]))
+		line(3, "rect:")
+		line(4, "serializedVersion: 2")
+		line(4, "x: %d", x)
+		line(4, "y: %d", sheet.Height-y-height)
+		line(4, "width: %d", width)
+		line(4, "height: %d", height)
+		line(3, "alignment: %d", alignment)
+		line(3, "pivot: {x: %s, y: %s}", formatFloat(pivotX), formatFloat(pivotY))
+		line(3, "border: {x: 0, y: 0, z: 0, w: 0}")
+		line(3, "outline: []")
+		line(3, "physicsShape: []")
+		line(3, "tessellationDetail: 0")
+		line(3, "bones: []")
+		line(3, "spriteID: %s", unityGUID(name, names[i]))
+		line(3, "internalID: %d", unityInternalID(name, names[i]))
+		line(3, "vertices: []")
+		line(3, "indices: ")
+		line(3, "edges: []")
+		line(3, "weights: []")
+	}
+	line(2, "outline: []")
+	line(2, "physicsShape: []")
+	line(2, "bones: []")
+	line(2, "spriteID: %s", unityGUID(name, ""))
+	line(2, "internalID: 0")
+	line(2, "vertices: []")
+	line(2, "indices: ")
+	line(2, "edges: []")
+	line(2, "weights: []")
+	line(2, "secondaryTextures: []")
+	if len(names) == 0 {
+		line(2, "nameFileIdTable: {}")
+	} else {
+		line(2, "nameFileIdTable:")
+		for _, spriteName := range names {
+			line(3, "%s: %d", yamlString(spriteName), unityInternalID(name, spriteName))
+		}
+	}
+	line(1, "spritePackingTag: ")
+	line(1, "userData: ")
+	line(1, "assetBundleName: ")
+	line(1, "assetBundleVariant: ")
+	return b.String()
+}
+
+// yamlString 在需要时为YAML字符串加引号
+func yamlString(s string) string {
+	if s == "" {
+		return `""`
+	}
+	plain := true
+	for i, r := range s {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			plain = false
+		case strings.ContainsRune(":#{}[],&*!|>'\"%@`", r):
+			plain = false
+		case i == 0 && (r == '-' || r == '?' || r == ' '):
+			plain = false
+		}
+	}
+	if strings.HasSuffix(s, " ") {
+		plain = false
+	}
+	// 避免被解析为数字、布尔值或null
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		plain = false
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		plain = false
+	}
+	if plain {
+		return s
+	}
+	return strconv.Quote(s)
+}
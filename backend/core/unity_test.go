@@ -0,0 +1,58 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestUnityMetaStableAcrossUploads(t *testing.T) {
+	sheet := testSheet(16, 16, Frame{Name: "a", Rect: NewRect(0, 0, 4, 4)})
+	e, _ := LookupExporter(UnityMeta)
+	export := func(baseName string) string {
+		files, err := e.Export(ExportContext{Sheet: sheet, BaseName: baseName, Name: "hero"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files[0].Content
+	}
+	// 同一图集的两次上传只有随机后缀不同
+	if first, second := export("hero_ab12CD34_1700000000"), export("hero_Zx98yW76_1700000100"); first != second {
+		t.Error("重复导出时GUID和internalID应保持不变")
+	}
+}
+
+func TestUnityMetaDuplicateNames(t *testing.T) {
+	sheet := testSheet(16, 16,
+		Frame{Name: "a.png", Rect: NewRect(0, 0, 4, 4)},
+		Frame{Name: "a.png", Rect: NewRect(8, 0, 4, 4)},
+	)
+	meta := GetUnityMeta(sheet, "sheet")
+
+	for _, pattern := range []string{`spriteID: (\w+)`, `internalID: (\d+)`, `name: (.+)`} {
+		seen := map[string]bool{}
+		for _, m := range regexp.MustCompile(`(?m)^      `+pattern).FindAllStringSubmatch(meta, -1) {
+			if seen[m[1]] {
+				t.Errorf("重复的%s", m[0])
+			}
+			seen[m[1]] = true
+		}
+		if len(seen) != 2 {
+			t.Errorf("%s 匹配到 %d 个精灵", pattern, len(seen))
+		}
+	}
+	if !strings.Contains(meta, "name: a_2\n") {
+		t.Error("重复的帧名称应加上序号")
+	}
+}
+
+func TestUnityMetaRect(t *testing.T) {
+	sheet := testSheet(16, 16, Frame{Name: "a", Rect: NewRect(2, 3, 4, 5)})
+	meta := GetUnityMeta(sheet, "sheet")
+	// Unity的原点在左下角
+	for _, want := range []string{"x: 2\n", "y: 8\n", "width: 4\n", "height: 5\n", "alignment: 0\n", "pivot: {x: 0.5, y: 0.5}"} {
+		if !strings.Contains(meta, want) {
+			t.Errorf("缺少 %q", want)
+		}
+	}
+}
@@ -38,6 +38,12 @@ func SetupRouter() *gin.Engine {
 		
 		// 文件下载接口
 		v1.GET("/download/:filename", controller.DownloadFile)
+
+		// 导出格式及其选项
+		v1.GET("/formats", controller.GetFormats)
+
+		// 导出JSON的Schema
+		v1.GET("/schema/sheet.json", controller.GetJSONSchema)
 	}
 
 	return r
@@ -50,4 +50,12 @@ func DirExists(path string) bool {
 // CreateDir 创建目录
 func CreateDir(path string) error {
 	return os.MkdirAll(path, 0755)
+}
+
+// ResetDir 删除目录中已有的文件并重新创建目录
+func ResetDir(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	return CreateDir(path)
 }
\ No newline at end of file
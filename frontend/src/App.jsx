@@ -1,4 +1,4 @@
-import { useState } from 'react'
+import { useEffect, useState } from 'react'
 import './App.css'
 
 function App() {
@@ -8,6 +8,92 @@ function App() {
   const [downloadUrl, setDownloadUrl] = useState('');
   const [statusMessage, setStatusMessage] = useState('');
   const [uploadedImagePath, setUploadedImagePath] = useState('');
+  const [formats, setFormats] = useState([]);
+  const [selectedFormats, setSelectedFormats] = useState([]);
+  const [formatOptions, setFormatOptions] = useState({});
+
+  // 从后端获取可用的导出格式，默认勾选默认导出的格式
+  useEffect(() => {
+    fetch('http://localhost:8080/api/v1/formats')
+      .then((response) => response.json())
+      .then((data) => {
+        setFormats(data.formats);
+        setSelectedFormats(data.formats.filter((format) => format.default).map((format) => format.id));
+      })
+      .catch((error) => console.error('获取导出格式错误:', error));
+  }, []);
+
+  const toggleFormat = (id) => {
+    setSelectedFormats((selected) =>
+      selected.includes(id) ? selected.filter((item) => item !== id) : [...selected, id]
+    );
+  };
+
+  const setOption = (id, name, value) => {
+    setFormatOptions((options) => ({ ...options, [id]: { ...options[id], [name]: value } }));
+  };
+
+  // 请求中的格式选项，清空的数字选项不发送，由后端使用默认值
+  const requestOptions = (id) => {
+    const options = formatOptions[id];
+    if (!options) {
+      return options;
+    }
+    const format = formats.find((item) => item.id === id);
+    return Object.fromEntries(
+      Object.entries(options).filter(([name, value]) => {
+        const option = format?.options?.find((item) => item.name === name);
+        const numeric = option?.type === 'integer' || option?.type === 'number';
+        return !(numeric && value === '');
+      })
+    );
+  };
+
+  // 按选项类型渲染输入框，未修改的选项使用默认值
+  const renderOption = (format, option) => {
+    const value = formatOptions[format.id]?.[option.name];
+    const key = `${format.id}.${option.name}`;
+    if (option.type === 'boolean') {
+      return (
+        <label key={key} className="format-option" title={option.description}>
+          <input
+            type="checkbox"
+            checked={value ?? option.default ?? false}
+            onChange={(event) => setOption(format.id, option.name, event.target.checked)}
+          />
+          {option.name}
+        </label>
+      );
+    }
+    if (option.enum) {
+      return (
+        <label key={key} className="format-option" title={option.description}>
+          {option.name}
+          <select
+            value={value ?? option.default ?? ''}
+            onChange={(event) => setOption(format.id, option.name, event.target.value)}
+          >
+            {option.enum.map((item) => <option key={item} value={item}>{item}</option>)}
+          </select>
+        </label>
+      );
+    }
+    const numeric = option.type === 'integer' || option.type === 'number';
+    return (
+      <label key={key} className="format-option" title={option.description}>
+        {option.name}
+        <input
+          type={numeric ? 'number' : 'text'}
+          value={value ?? option.default ?? ''}
+          placeholder={option.description}
+          onChange={(event) => {
+            const text = event.target.value;
+            setOption(format.id, option.name, numeric && text !== '' ? Number(text) : text);
+          }}
+        />
+      </label>
+    );
+  };
 
   const handleFileChange = (event) => {
     const file = event.target.files[0];
@@ -81,7 +167,10 @@ function App() {
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify({ filename: uploadedImagePath }),
+        body: JSON.stringify({
+          filename: uploadedImagePath,
+          formats: selectedFormats.map((id) => ({ id, options: requestOptions(id) })),
+        }),
       });
       
       if (response.ok) {
@@ -89,7 +178,8 @@ function App() {
         setDownloadUrl(`http://localhost:8080${data.download_url}`); // 后端返回的是相对路径，需要拼接完整URL
         setStatusMessage('图片处理完成，可以下载结果');
       } else {
-        setStatusMessage('图片处理失败');
+        const data = await response.json().catch(() => ({}));
+        setStatusMessage(data.error || '图片处理失败');
       }
     } catch (error) {
       console.error('处理错误:', error);
@@ -148,6 +238,27 @@ function App() {
           )}
         </section>
         
+        <section className="formats-section">
+          <h2>导出格式</h2>
+          {formats.map((format) => (
+            <div key={format.id} className="format-item">
+              <label title={format.description}>
+                <input
+                  type="checkbox"
+                  checked={selectedFormats.includes(format.id)}
+                  onChange={() => toggleFormat(format.id)}
+                />
+                {format.name}
+              </label>
+              {selectedFormats.includes(format.id) && format.options.length > 0 && (
+                <div className="format-options">
+                  {format.options.map((option) => renderOption(format, option))}
+                </div>
+              )}
+            </div>
+          ))}
+        </section>
+
         <section className="actions-section">
           <h2>操作</h2>
           <button 